@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"regexp"
 	"strings"
 
 	"github.com/MohamTahaB/interpreter-go/token"
@@ -36,6 +37,16 @@ type LetStatement struct {
 type Identifier struct {
 	Token token.Token
 	Value string
+
+	// Set by the resolver for identifiers bound in a function: number of environments to walk up, and slot in that environment.
+	// Unresolved identifiers are globals, looked up by name.
+	Resolved bool
+	Depth    int
+	Slot     int
+
+	// Set along with the position: the enclosing binding of the same name, looked up while the resolved one is not made yet,
+	// as the name still stands for it then. The last fallback is unresolved, looked up by name.
+	Fallback *Identifier
 }
 
 type IntegerLiteral struct {
@@ -69,9 +80,15 @@ type IfExpression struct {
 }
 
 type FunctionLiteral struct {
-	Token      token.Token
+	Token token.Token
+
+	// Name of the declaration, let binding or method the function is defined by, empty for an anonymous function.
+	Name       string
 	Parameters []*Identifier
 	Body       *BlockStatement
+
+	// Number of slots the resolver allocated for the parameters and locals.
+	FrameSize int
 }
 
 type StringLiteral struct {
@@ -79,6 +96,16 @@ type StringLiteral struct {
 	Value string
 }
 
+// Regex literal, /pattern/flags.
+type RegexLiteral struct {
+	Token   token.Token
+	Pattern string
+	Flags   string
+
+	// Set by the evaluator the first time the literal compiles, so that evaluating it again does not recompile it.
+	Compiled *regexp.Regexp
+}
+
 type BlockStatement struct {
 	Token      token.Token
 	Statements []Statement
@@ -88,6 +115,9 @@ type CallExpression struct {
 	Token     token.Token
 	Function  Expression
 	Arguments []Expression
+
+	// Set by the parser for calls whose value is the value of the enclosing function.
+	Tail bool
 }
 
 func (p *Program) TokenLiteral() string {
@@ -112,6 +142,11 @@ func (ls *LetStatement) TokenLiteral() string {
 	return ls.Token.Literal
 }
 
+// Whether the binding was declared with const, and cannot be assigned again.
+func (ls *LetStatement) Constant() bool {
+	return ls.Token.Type == token.CONST
+}
+
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 
@@ -273,6 +308,16 @@ func (fl *FunctionLiteral) String() string {
 	return out.String()
 }
 
+func (rl *RegexLiteral) expressionNode() {}
+
+func (rl *RegexLiteral) TokenLiteral() string {
+	return rl.Token.Literal
+}
+
+func (rl *RegexLiteral) String() string {
+	return "/" + rl.Pattern + "/" + rl.Flags
+}
+
 func (sl *StringLiteral) expressionNode() {}
 
 func (sl *StringLiteral) TokenLiteral() string {
@@ -322,3 +367,434 @@ func (es *ExpressionStatement) String() string {
 	}
 	return ""
 }
+
+// Declaration of a struct type and its fields: struct Point { x, y }
+type StructStatement struct {
+	Token  token.Token
+	Name   *Identifier
+	Fields []*Identifier
+}
+
+func (ss *StructStatement) statementNode() {}
+func (ss *StructStatement) TokenLiteral() string {
+	return ss.Token.Literal
+}
+
+func (ss *StructStatement) String() string {
+	var out strings.Builder
+
+	fields := []string{}
+	for _, f := range ss.Fields {
+		fields = append(fields, f.String())
+	}
+
+	out.WriteString(ss.TokenLiteral() + " ")
+	out.WriteString(ss.Name.String())
+	out.WriteString(" { ")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// Construction of a struct instance: Point{x: 1, y: 2}
+type StructLiteral struct {
+	Token  token.Token
+	Struct Expression
+	Names  []*Identifier
+	Values []Expression
+}
+
+func (sl *StructLiteral) expressionNode() {}
+func (sl *StructLiteral) TokenLiteral() string {
+	return sl.Token.Literal
+}
+
+func (sl *StructLiteral) String() string {
+	var out strings.Builder
+
+	fields := []string{}
+	for idx, name := range sl.Names {
+		fields = append(fields, name.String()+": "+sl.Values[idx].String())
+	}
+
+	out.WriteString(sl.Struct.String())
+	out.WriteString("{")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// Access to a member of an object: p.x
+type MemberExpression struct {
+	Token    token.Token
+	Object   Expression
+	Property *Identifier
+}
+
+func (me *MemberExpression) expressionNode() {}
+func (me *MemberExpression) TokenLiteral() string {
+	return me.Token.Literal
+}
+
+func (me *MemberExpression) String() string {
+	return me.Object.String() + "." + me.Property.String()
+}
+
+// Assignment to a member of an object: p.x = 3
+type AssignExpression struct {
+	Token  token.Token
+	Target Expression
+	Value  Expression
+}
+
+func (ae *AssignExpression) expressionNode() {}
+func (ae *AssignExpression) TokenLiteral() string {
+	return ae.Token.Literal
+}
+
+func (ae *AssignExpression) String() string {
+	return "(" + ae.Target.String() + " = " + ae.Value.String() + ")"
+}
+
+// Declaration of a class, its optional superclass and its methods:
+// class Point extends Base { init(x, y) { ... } sum() { ... } }
+type ClassStatement struct {
+	Token       token.Token
+	Name        *Identifier
+	Superclass  *Identifier
+	MethodNames []*Identifier
+	Methods     []*FunctionLiteral
+}
+
+func (cs *ClassStatement) statementNode() {}
+func (cs *ClassStatement) TokenLiteral() string {
+	return cs.Token.Literal
+}
+
+func (cs *ClassStatement) String() string {
+	var out strings.Builder
+
+	out.WriteString(cs.TokenLiteral() + " ")
+	out.WriteString(cs.Name.String())
+	if cs.Superclass != nil {
+		out.WriteString(" extends " + cs.Superclass.String())
+	}
+	out.WriteString(" { ")
+
+	for idx, method := range cs.Methods {
+		params := []string{}
+		for _, p := range method.Parameters {
+			params = append(params, p.String())
+		}
+
+		out.WriteString(cs.MethodNames[idx].String())
+		out.WriteString("(" + strings.Join(params, ", ") + ") ")
+		out.WriteString(method.Body.String() + " ")
+	}
+
+	out.WriteString("}")
+
+	return out.String()
+}
+
+type ThrowStatement struct {
+	Token token.Token
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode() {}
+func (ts *ThrowStatement) TokenLiteral() string {
+	return ts.Token.Literal
+}
+
+func (ts *ThrowStatement) String() string {
+	return ts.TokenLiteral() + " " + ts.Value.String() + ";"
+}
+
+// try { } catch (e) { } finally { }. CatchBlock or FinallyBlock is nil when left out.
+type TryStatement struct {
+	Token          token.Token
+	Block          *BlockStatement
+	CatchParameter *Identifier
+	CatchBlock     *BlockStatement
+	FinallyBlock   *BlockStatement
+}
+
+func (ts *TryStatement) statementNode() {}
+func (ts *TryStatement) TokenLiteral() string {
+	return ts.Token.Literal
+}
+
+func (ts *TryStatement) String() string {
+	var out strings.Builder
+
+	out.WriteString("try { " + ts.Block.String() + " }")
+	if ts.CatchBlock != nil {
+		out.WriteString(" catch (" + ts.CatchParameter.String() + ") { " + ts.CatchBlock.String() + " }")
+	}
+	if ts.FinallyBlock != nil {
+		out.WriteString(" finally { " + ts.FinallyBlock.String() + " }")
+	}
+
+	return out.String()
+}
+
+// fn name(a, b) { }, binding the function to its name in the whole block it is declared in.
+type FunctionStatement struct {
+	Token    token.Token
+	Name     *Identifier
+	Function *FunctionLiteral
+}
+
+func (fs *FunctionStatement) statementNode() {}
+func (fs *FunctionStatement) TokenLiteral() string {
+	return fs.Token.Literal
+}
+
+func (fs *FunctionStatement) String() string {
+	params := []string{}
+	for _, p := range fs.Function.Parameters {
+		params = append(params, p.String())
+	}
+
+	return fs.TokenLiteral() + " " + fs.Name.String() + "(" + strings.Join(params, ", ") + ") " + fs.Function.Body.String()
+}
+
+type ArrayLiteral struct {
+	Token    token.Token
+	Elements []Expression
+}
+
+func (al *ArrayLiteral) expressionNode() {}
+func (al *ArrayLiteral) TokenLiteral() string {
+	return al.Token.Literal
+}
+
+func (al *ArrayLiteral) String() string {
+	return "[" + joinExpressions(al.Elements) + "]"
+}
+
+// {k: v}, the pairs in the order they are written.
+type HashLiteral struct {
+	Token  token.Token
+	Keys   []Expression
+	Values []Expression
+}
+
+func (hl *HashLiteral) expressionNode() {}
+func (hl *HashLiteral) TokenLiteral() string {
+	return hl.Token.Literal
+}
+
+func (hl *HashLiteral) String() string {
+	return "{" + joinPairs(hl.Keys, hl.Values) + "}"
+}
+
+type IndexExpression struct {
+	Token token.Token
+	Left  Expression
+	Index Expression
+}
+
+func (ie *IndexExpression) expressionNode() {}
+func (ie *IndexExpression) TokenLiteral() string {
+	return ie.Token.Literal
+}
+
+func (ie *IndexExpression) String() string {
+	return "(" + ie.Left.String() + "[" + ie.Index.String() + "])"
+}
+
+// Name of the pattern matching any value without binding it.
+const WILDCARD = "_"
+
+// Patterns are expressions of their own: literals match equal values, identifiers bind any value, the wildcard excepted,
+// and array and hash patterns match the elements of arrays and hashes against their own patterns.
+
+// Returns the identifiers a pattern binds, the wildcard left out.
+func PatternNames(pattern Expression) []*Identifier {
+	names := []*Identifier{}
+
+	switch pattern := pattern.(type) {
+	case *Identifier:
+		if pattern.Value != WILDCARD {
+			names = append(names, pattern)
+		}
+
+	case *ArrayPattern:
+		for _, element := range pattern.Elements {
+			names = append(names, PatternNames(element)...)
+		}
+		if pattern.Rest != nil {
+			names = append(names, PatternNames(pattern.Rest)...)
+		}
+
+	case *HashPattern:
+		for _, value := range pattern.Values {
+			names = append(names, PatternNames(value)...)
+		}
+	}
+
+	return names
+}
+
+// [p, q], matching arrays of as many elements, or [p, ...rest], matching arrays of at least as many, rest binding the others.
+type ArrayPattern struct {
+	Token    token.Token
+	Elements []Expression
+	Rest     *Identifier
+}
+
+func (ap *ArrayPattern) expressionNode() {}
+func (ap *ArrayPattern) TokenLiteral() string {
+	return ap.Token.Literal
+}
+
+func (ap *ArrayPattern) String() string {
+	if ap.Rest == nil {
+		return "[" + joinExpressions(ap.Elements) + "]"
+	}
+
+	if len(ap.Elements) == 0 {
+		return "[..." + ap.Rest.String() + "]"
+	}
+
+	return "[" + joinExpressions(ap.Elements) + ", ..." + ap.Rest.String() + "]"
+}
+
+// {k: p}, matching hashes holding at least the keys, whatever others they hold, and objects holding such members.
+// {name} is short for {"name": name}.
+type HashPattern struct {
+	Token  token.Token
+	Keys   []Expression
+	Values []Expression
+}
+
+func (hp *HashPattern) expressionNode() {}
+func (hp *HashPattern) TokenLiteral() string {
+	return hp.Token.Literal
+}
+
+func (hp *HashPattern) String() string {
+	return "{" + joinPairs(hp.Keys, hp.Values) + "}"
+}
+
+// pattern if guard => body. Guard is nil when left out.
+type MatchArm struct {
+	Pattern Expression
+	Guard   Expression
+	Body    Expression
+}
+
+type MatchExpression struct {
+	Token token.Token
+	Value Expression
+	Arms  []*MatchArm
+}
+
+func (me *MatchExpression) expressionNode() {}
+func (me *MatchExpression) TokenLiteral() string {
+	return me.Token.Literal
+}
+
+func (me *MatchExpression) String() string {
+	arms := []string{}
+	for _, arm := range me.Arms {
+		out := arm.Pattern.String()
+		if arm.Guard != nil {
+			out += " if " + arm.Guard.String()
+		}
+		arms = append(arms, out+" => "+arm.Body.String())
+	}
+
+	return me.TokenLiteral() + " (" + me.Value.String() + ") { " + strings.Join(arms, ", ") + " }"
+}
+
+func joinExpressions(expressions []Expression) string {
+	out := []string{}
+	for _, exp := range expressions {
+		out = append(out, exp.String())
+	}
+
+	return strings.Join(out, ", ")
+}
+
+func joinPairs(keys, values []Expression) string {
+	pairs := []string{}
+	for idx, key := range keys {
+		pairs = append(pairs, key.String()+": "+values[idx].String())
+	}
+
+	return strings.Join(pairs, ", ")
+}
+
+// let pattern = value, binding the names of an array or hash pattern.
+type LetPatternStatement struct {
+	Token   token.Token
+	Pattern Expression
+	Value   Expression
+}
+
+func (lp *LetPatternStatement) statementNode() {}
+func (lp *LetPatternStatement) TokenLiteral() string {
+	return lp.Token.Literal
+}
+
+func (lp *LetPatternStatement) Constant() bool {
+	return lp.Token.Type == token.CONST
+}
+
+func (lp *LetPatternStatement) String() string {
+	return lp.TokenLiteral() + " " + lp.Pattern.String() + " = " + lp.Value.String() + ";"
+}
+
+// import "path" as name, binding the module at the path to the name.
+type ImportStatement struct {
+	Token token.Token
+	Path  string
+	Name  *Identifier
+}
+
+func (is *ImportStatement) statementNode() {}
+func (is *ImportStatement) TokenLiteral() string {
+	return is.Token.Literal
+}
+
+func (is *ImportStatement) String() string {
+	return is.TokenLiteral() + " \"" + is.Path + "\" as " + is.Name.String() + ";"
+}
+
+// export followed by a declaration, whose names the module exposes as members.
+type ExportStatement struct {
+	Token       token.Token
+	Declaration Statement
+}
+
+func (es *ExportStatement) statementNode() {}
+func (es *ExportStatement) TokenLiteral() string {
+	return es.Token.Literal
+}
+
+func (es *ExportStatement) String() string {
+	return es.TokenLiteral() + " " + es.Declaration.String()
+}
+
+// Names the exported declaration binds.
+func (es *ExportStatement) Names() []*Identifier {
+	switch declaration := es.Declaration.(type) {
+	case *LetStatement:
+		return []*Identifier{declaration.Name}
+	case *LetPatternStatement:
+		return PatternNames(declaration.Pattern)
+	case *FunctionStatement:
+		return []*Identifier{declaration.Name}
+	case *StructStatement:
+		return []*Identifier{declaration.Name}
+	case *ClassStatement:
+		return []*Identifier{declaration.Name}
+	}
+
+	return nil
+}
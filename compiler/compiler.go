@@ -0,0 +1,493 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/MohamTahaB/interpreter-go/ast"
+	"github.com/MohamTahaB/interpreter-go/object"
+	"github.com/MohamTahaB/interpreter-go/token"
+)
+
+const (
+	UNSUPPORTED_NODE_MSG = "unsupported node: %T"
+	UNKNOWN_OP_MSG       = "unknown operator: %s"
+	CONSTANT_REDECLARED  = "cannot redeclare constant %s"
+	CAPTURED_REBINDING   = "unsupported binding of %s after a function referring to it"
+)
+
+// Error of a program using a construct the compiler does not support, which only the evaluator runs.
+type UnsupportedError struct {
+	Message string
+}
+
+func (e *UnsupportedError) Error() string {
+	return e.Message
+}
+
+func unsupported(format string, a ...interface{}) error {
+	return &UnsupportedError{Message: fmt.Sprintf(format, a...)}
+}
+
+var (
+	INFIX_OPERATORS_OPCODES = map[string]Opcode{
+		token.PLUS:  OpAdd,
+		token.MINUS: OpSub,
+		token.TIMES: OpMul,
+		token.SLASH: OpDiv,
+
+		token.EQ:  OpEqual,
+		token.NEQ: OpNotEqual,
+		token.LT:  OpLessThan,
+		token.LEQ: OpLessEqual,
+		token.GT:  OpGreaterThan,
+		token.GEQ: OpGreaterEqual,
+		token.IN:  OpIn,
+	}
+
+	PREFIX_OPERATORS_OPCODES = map[string]Opcode{
+		token.MINUS: OpMinus,
+		token.NEG:   OpBang,
+	}
+)
+
+// Output of the compiler, and input of the virtual machine.
+type Bytecode struct {
+	Instructions Instructions
+	Constants    []object.Object
+
+	// Names of the globals, by slot, used to report unbound identifiers.
+	GlobalNames []string
+}
+
+type EmittedInstruction struct {
+	Opcode   Opcode
+	Position int
+}
+
+// Instructions being emitted for the function (or program) currently compiled.
+type CompilationScope struct {
+	instructions        Instructions
+	lastInstruction     EmittedInstruction
+	previousInstruction EmittedInstruction
+}
+
+type Compiler struct {
+	constants   []object.Object
+	symbolTable *SymbolTable
+
+	scopes     []CompilationScope
+	scopeIndex int
+}
+
+func New() *Compiler {
+	mainScope := CompilationScope{
+		instructions: Instructions{},
+	}
+
+	return &Compiler{
+		constants:   []object.Object{},
+		symbolTable: NewSymbolTable(),
+		scopes:      []CompilationScope{mainScope},
+	}
+}
+
+// Returns a compiler that keeps on the symbols and constants of a previous run, as the REPL needs.
+func NewWithState(s *SymbolTable, constants []object.Object) *Compiler {
+	c := New()
+	c.symbolTable = s
+	c.constants = constants
+	return c
+}
+
+func (c *Compiler) Compile(node ast.Node) error {
+	switch node := node.(type) {
+	// Statements
+	case *ast.Program:
+		if err := c.compileStatements(node.Statements); err != nil {
+			return err
+		}
+
+		// A trailing let or function declaration evaluates to null, as in the evaluator.
+		if endsWithBinding(node.Statements) {
+			c.emit(OpNull)
+			c.emit(OpPop)
+		}
+
+	case *ast.ExpressionStatement:
+		if err := c.Compile(node.Expression); err != nil {
+			return err
+		}
+		c.emit(OpPop)
+
+	case *ast.BlockStatement:
+		return c.compileStatements(node.Statements)
+
+	case *ast.LetStatement:
+		var err error
+		if fn, ok := node.Value.(*ast.FunctionLiteral); ok {
+			err = c.compileFunction(fn, node.Name.Value)
+		} else {
+			err = c.Compile(node.Value)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Defined once the value is compiled, so that the value still sees any outer binding of the same name.
+		return c.bind(node.Name.Value, node.Constant())
+
+	case *ast.FunctionStatement:
+		if err := c.compileFunction(node.Function, node.Name.Value); err != nil {
+			return err
+		}
+
+		return c.bind(node.Name.Value, false)
+
+	case *ast.ReturnStatement:
+		if err := c.Compile(node.ReturnValue); err != nil {
+			return err
+		}
+		c.emit(OpReturnValue)
+
+	// Expressions
+	case *ast.IntegerLiteral:
+		integer := object.NewInteger(node.Value)
+		c.emit(OpConstant, c.addConstant(integer))
+
+	case *ast.StringLiteral:
+		str := &object.String{Value: node.Value}
+		c.emit(OpConstant, c.addConstant(str))
+
+	case *ast.Boolean:
+		if node.Value {
+			c.emit(OpTrue)
+		} else {
+			c.emit(OpFalse)
+		}
+
+	case *ast.PrefixExpression:
+		op, ok := PREFIX_OPERATORS_OPCODES[node.Operator]
+		if !ok {
+			return fmt.Errorf(UNKNOWN_OP_MSG, node.Operator)
+		}
+
+		if err := c.Compile(node.Right); err != nil {
+			return err
+		}
+		c.emit(op)
+
+	case *ast.InfixExpression:
+		op, ok := INFIX_OPERATORS_OPCODES[node.Operator]
+		if !ok {
+			return fmt.Errorf(UNKNOWN_OP_MSG, node.Operator)
+		}
+
+		if err := c.Compile(node.Left); err != nil {
+			return err
+		}
+		if err := c.Compile(node.Right); err != nil {
+			return err
+		}
+		c.emit(op)
+
+	case *ast.IfExpression:
+		return c.compileConditionalExpression(node)
+
+	case *ast.Identifier:
+		c.loadSymbol(c.symbolTable.ResolveOrDeclare(node.Value))
+
+	case *ast.FunctionLiteral:
+		return c.compileFunction(node, "")
+
+	case *ast.CallExpression:
+		if err := c.Compile(node.Function); err != nil {
+			return err
+		}
+
+		for _, arg := range node.Arguments {
+			if err := c.Compile(arg); err != nil {
+				return err
+			}
+		}
+
+		c.emit(OpCall, len(node.Arguments))
+
+	default:
+		return unsupported(UNSUPPORTED_NODE_MSG, node)
+	}
+
+	return nil
+}
+
+func (c *Compiler) Bytecode() *Bytecode {
+	global := c.symbolTable
+	for global.Outer != nil {
+		global = global.Outer
+	}
+
+	return &Bytecode{
+		Instructions: c.currentInstructions(),
+		Constants:    c.constants,
+		GlobalNames:  global.LocalNames(),
+	}
+}
+
+func (c *Compiler) compileConditionalExpression(node *ast.IfExpression) error {
+	if err := c.Compile(node.Condition); err != nil {
+		return err
+	}
+
+	// Placeholder operand, patched once the consequence is emitted.
+	jumpNotTruthyPos := c.emit(OpJumpNotTruthy, 9999)
+
+	if err := c.compileBlockValue(node.Consequence); err != nil {
+		return err
+	}
+
+	jumpPos := c.emit(OpJump, 9999)
+	c.changeOperand(jumpNotTruthyPos, len(c.currentInstructions()))
+
+	if node.Alternative == nil {
+		c.emit(OpNull)
+	} else if err := c.compileBlockValue(node.Alternative); err != nil {
+		return err
+	}
+
+	c.changeOperand(jumpPos, len(c.currentInstructions()))
+
+	return nil
+}
+
+// Defines the name and sets it to the value on top of the stack. A constant cannot be bound again in the same scope, nor a name a function compiled before refers to.
+func (c *Compiler) bind(name string, constant bool) error {
+	if c.symbolTable.DefinedConstant(name) {
+		return fmt.Errorf(CONSTANT_REDECLARED, name)
+	}
+	if c.symbolTable.Rebinds(name) {
+		return unsupported(CAPTURED_REBINDING, name)
+	}
+
+	var symbol Symbol
+	if constant {
+		symbol = c.symbolTable.DefineConstant(name)
+	} else {
+		symbol = c.symbolTable.Define(name)
+	}
+
+	if symbol.Scope == GlobalScope {
+		c.emit(OpSetGlobal, symbol.Index)
+	} else {
+		c.emit(OpSetLocal, symbol.Index)
+	}
+
+	return nil
+}
+
+// Compiles a block so that it leaves its value on the stack, as an if branch does. Names bound in the block go out of scope with it.
+func (c *Compiler) compileBlockValue(block *ast.BlockStatement) error {
+	c.symbolTable.EnterBlock()
+	err := c.Compile(block)
+	c.symbolTable.LeaveBlock()
+
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case endsWithBinding(block.Statements):
+		c.emit(OpNull)
+	case c.lastInstructionIs(OpPop):
+		c.removeLastPop()
+	case !c.lastInstructionIs(OpReturnValue):
+		c.emit(OpNull)
+	}
+
+	return nil
+}
+
+// Compiles a list of statements, its function declarations first, as they are bound in the whole list.
+func (c *Compiler) compileStatements(statements []ast.Statement) error {
+	for _, s := range statements {
+		if _, ok := s.(*ast.FunctionStatement); ok {
+			if err := c.Compile(s); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, s := range statements {
+		if _, ok := s.(*ast.FunctionStatement); !ok {
+			if err := c.Compile(s); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Whether the last of the statements binds a name, leaving no value behind.
+func endsWithBinding(statements []ast.Statement) bool {
+	if len(statements) == 0 {
+		return false
+	}
+
+	switch statements[len(statements)-1].(type) {
+	case *ast.LetStatement, *ast.FunctionStatement:
+		return true
+	}
+
+	return false
+}
+
+func (c *Compiler) compileFunction(node *ast.FunctionLiteral, name string) error {
+	c.enterScope()
+
+	if name != "" {
+		c.symbolTable.DefineFunctionName(name)
+	}
+
+	for _, p := range node.Parameters {
+		c.symbolTable.Define(p.Value)
+	}
+
+	if err := c.Compile(node.Body); err != nil {
+		return err
+	}
+
+	if c.lastInstructionIs(OpPop) && !endsWithBinding(node.Body.Statements) {
+		c.replaceLastPopWithReturn()
+	}
+	if !c.lastInstructionIs(OpReturnValue) {
+		c.emit(OpReturn)
+	}
+
+	freeSymbols := c.symbolTable.FreeSymbols
+	numLocals := c.symbolTable.numDefinitions
+	localNames := c.symbolTable.LocalNames()
+	instructions := c.leaveScope()
+
+	for _, s := range freeSymbols {
+		c.loadSymbol(s)
+	}
+
+	compiledFn := &object.CompiledFunction{
+		Instructions:  instructions,
+		NumLocals:     numLocals,
+		NumParameters: len(node.Parameters),
+		LocalNames:    localNames,
+		Name:          node.Name,
+		Parameters:    node.Parameters,
+		Body:          node.Body,
+	}
+
+	c.emit(OpClosure, c.addConstant(compiledFn), len(freeSymbols))
+
+	return nil
+}
+
+func (c *Compiler) loadSymbol(s Symbol) {
+	switch s.Scope {
+	case GlobalScope:
+		c.emit(OpGetGlobal, s.Index)
+	case LocalScope:
+		c.emit(OpGetLocal, s.Index)
+	case FreeScope:
+		c.emit(OpGetFree, s.Index)
+	case FunctionScope:
+		c.emit(OpCurrentClosure)
+	}
+}
+
+func (c *Compiler) addConstant(obj object.Object) int {
+	c.constants = append(c.constants, obj)
+	return len(c.constants) - 1
+}
+
+// Emits an instruction in the current scope, and returns its position.
+func (c *Compiler) emit(op Opcode, operands ...int) int {
+	ins := Make(op, operands...)
+	pos := c.addInstruction(ins)
+
+	c.setLastInstruction(op, pos)
+
+	return pos
+}
+
+func (c *Compiler) addInstruction(ins []byte) int {
+	posNewInstruction := len(c.currentInstructions())
+	c.scopes[c.scopeIndex].instructions = append(c.currentInstructions(), ins...)
+
+	return posNewInstruction
+}
+
+func (c *Compiler) setLastInstruction(op Opcode, pos int) {
+	previous := c.scopes[c.scopeIndex].lastInstruction
+	last := EmittedInstruction{Opcode: op, Position: pos}
+
+	c.scopes[c.scopeIndex].previousInstruction = previous
+	c.scopes[c.scopeIndex].lastInstruction = last
+}
+
+func (c *Compiler) currentInstructions() Instructions {
+	return c.scopes[c.scopeIndex].instructions
+}
+
+func (c *Compiler) lastInstructionIs(op Opcode) bool {
+	if len(c.currentInstructions()) == 0 {
+		return false
+	}
+
+	return c.scopes[c.scopeIndex].lastInstruction.Opcode == op
+}
+
+func (c *Compiler) removeLastPop() {
+	last := c.scopes[c.scopeIndex].lastInstruction
+	previous := c.scopes[c.scopeIndex].previousInstruction
+
+	c.scopes[c.scopeIndex].instructions = c.currentInstructions()[:last.Position]
+	c.scopes[c.scopeIndex].lastInstruction = previous
+}
+
+func (c *Compiler) replaceLastPopWithReturn() {
+	lastPos := c.scopes[c.scopeIndex].lastInstruction.Position
+	c.replaceInstruction(lastPos, Make(OpReturnValue))
+
+	c.scopes[c.scopeIndex].lastInstruction.Opcode = OpReturnValue
+}
+
+func (c *Compiler) replaceInstruction(pos int, newInstruction []byte) {
+	ins := c.currentInstructions()
+
+	for i := 0; i < len(newInstruction); i++ {
+		ins[pos+i] = newInstruction[i]
+	}
+}
+
+func (c *Compiler) changeOperand(opPos int, operand int) {
+	op := Opcode(c.currentInstructions()[opPos])
+	newInstruction := Make(op, operand)
+
+	c.replaceInstruction(opPos, newInstruction)
+}
+
+func (c *Compiler) enterScope() {
+	scope := CompilationScope{
+		instructions: Instructions{},
+	}
+	c.scopes = append(c.scopes, scope)
+	c.scopeIndex++
+
+	c.symbolTable = NewEnclosedSymbolTable(c.symbolTable)
+}
+
+func (c *Compiler) leaveScope() Instructions {
+	instructions := c.currentInstructions()
+
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.scopeIndex--
+
+	c.symbolTable = c.symbolTable.Outer
+
+	return instructions
+}
@@ -0,0 +1,377 @@
+package compiler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/MohamTahaB/interpreter-go/ast"
+	"github.com/MohamTahaB/interpreter-go/lexer"
+	"github.com/MohamTahaB/interpreter-go/object"
+	"github.com/MohamTahaB/interpreter-go/parser"
+)
+
+type compilerTestCase struct {
+	input                string
+	expectedConstants    []interface{}
+	expectedInstructions []Instructions
+}
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		op       Opcode
+		operands []int
+		expected []byte
+	}{
+		{OpConstant, []int{65534}, []byte{byte(OpConstant), 255, 254}},
+		{OpAdd, []int{}, []byte{byte(OpAdd)}},
+		{OpGetLocal, []int{255}, []byte{byte(OpGetLocal), 255}},
+		{OpClosure, []int{65534, 255}, []byte{byte(OpClosure), 255, 254, 255}},
+	}
+
+	for _, tt := range tests {
+		instruction := Make(tt.op, tt.operands...)
+
+		if len(instruction) != len(tt.expected) {
+			t.Errorf("instruction has wrong length. Expected=%d, got=%d", len(tt.expected), len(instruction))
+		}
+
+		for i, b := range tt.expected {
+			if instruction[i] != b {
+				t.Errorf("wrong byte at pos %d. Expected=%d, got=%d", i, b, instruction[i])
+			}
+		}
+	}
+}
+
+func TestInstructionsString(t *testing.T) {
+	instructions := []Instructions{
+		Make(OpAdd),
+		Make(OpGetLocal, 1),
+		Make(OpConstant, 2),
+		Make(OpConstant, 65535),
+		Make(OpClosure, 65535, 255),
+	}
+
+	expected := `0000 OpAdd
+0001 OpGetLocal 1
+0003 OpConstant 2
+0006 OpConstant 65535
+0009 OpClosure 65535 255
+`
+
+	concatted := Instructions{}
+	for _, ins := range instructions {
+		concatted = append(concatted, ins...)
+	}
+
+	if concatted.String() != expected {
+		t.Errorf("instructions wrongly formatted.\nExpected=%q\nGot=%q", expected, concatted.String())
+	}
+}
+
+func TestIntegerArithmetic(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "1 + 2",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []Instructions{
+				Make(OpConstant, 0),
+				Make(OpConstant, 1),
+				Make(OpAdd),
+				Make(OpPop),
+			},
+		},
+		{
+			input:             "-1 * 2",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []Instructions{
+				Make(OpConstant, 0),
+				Make(OpMinus),
+				Make(OpConstant, 1),
+				Make(OpMul),
+				Make(OpPop),
+			},
+		},
+		{
+			input:             "!true == false",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []Instructions{
+				Make(OpTrue),
+				Make(OpBang),
+				Make(OpFalse),
+				Make(OpEqual),
+				Make(OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestConditionals(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "if (true) { 10 }; 3333;",
+			expectedConstants: []interface{}{10, 3333},
+			expectedInstructions: []Instructions{
+				// 0000
+				Make(OpTrue),
+				// 0001
+				Make(OpJumpNotTruthy, 10),
+				// 0004
+				Make(OpConstant, 0),
+				// 0007
+				Make(OpJump, 11),
+				// 0010
+				Make(OpNull),
+				// 0011
+				Make(OpPop),
+				// 0012
+				Make(OpConstant, 1),
+				// 0015
+				Make(OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestLetStatementsScopes(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "let one = 1; let two = one; two;",
+			expectedConstants: []interface{}{1},
+			expectedInstructions: []Instructions{
+				Make(OpConstant, 0),
+				Make(OpSetGlobal, 0),
+				Make(OpGetGlobal, 0),
+				Make(OpSetGlobal, 1),
+				Make(OpGetGlobal, 1),
+				Make(OpPop),
+			},
+		},
+		{
+			input: "fn() { let num = 55; num }",
+			expectedConstants: []interface{}{
+				55,
+				[]Instructions{
+					Make(OpConstant, 0),
+					Make(OpSetLocal, 0),
+					Make(OpGetLocal, 0),
+					Make(OpReturnValue),
+				},
+			},
+			expectedInstructions: []Instructions{
+				Make(OpClosure, 1, 0),
+				Make(OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestBlockScopes(t *testing.T) {
+	// The block's binding takes a slot of its own, and the outer one is visible again once the block is left.
+	tests := []compilerTestCase{
+		{
+			input:             "let a = 1; if (true) { let a = 2; }; a",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []Instructions{
+				Make(OpConstant, 0),
+				Make(OpSetGlobal, 0),
+				Make(OpTrue),
+				Make(OpJumpNotTruthy, 20),
+				Make(OpConstant, 1),
+				Make(OpSetGlobal, 1),
+				Make(OpNull),
+				Make(OpJump, 21),
+				Make(OpNull),
+				Make(OpPop),
+				Make(OpGetGlobal, 0),
+				Make(OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestConstantRedeclaration(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"const a = 1; let a = 2;", true},
+		{"fn() { const a = 1; const a = 2; }", true},
+		{"const a = 1; if (true) { let a = 2; }", false},
+		{"let a = 1; const a = 2;", false},
+	}
+
+	for _, tt := range tests {
+		err := New().Compile(parse(tt.input))
+		if tt.wantErr && (err == nil || err.Error() != "cannot redeclare constant a") {
+			t.Errorf("input %q: wrong error. Got=%v", tt.input, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("input %q: unexpected error %s", tt.input, err)
+		}
+	}
+}
+
+func TestCapturedRebinding(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{"let f = fn(a) { let g = fn() { a + b }; let b = 2; g() }; f(1)", "b"},
+		{"fn() { let a = 1; let g = fn() { a }; let a = 2; g() }", "a"},
+		{"fn() { let g = fn() { fn() { x } }; let x = 1; }", "x"},
+		{"fn() { if (true) { let g = fn() { x }; let x = 1; g() } }", "x"},
+		{"fn() { fn a() { b() } fn b() { 1 } a() }", "b"},
+		{"fn() { let a = 1; let g = fn() { a }; g() }", ""},
+		{"fn() { let g = fn() { x }; if (true) { let x = 2; }; g() }", ""},
+		{"let g = fn() { x }; let x = 1; g()", ""},
+		{"fn(a) { let a = a * 2; fn() { a } }", ""},
+	}
+
+	for _, tt := range tests {
+		err := New().Compile(parse(tt.input))
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("input %q: unexpected error %s", tt.input, err)
+			}
+			continue
+		}
+
+		expected := fmt.Sprintf(CAPTURED_REBINDING, tt.wantErr)
+		if err == nil || err.Error() != expected {
+			t.Errorf("input %q: wrong error. Expected=%q, got=%v", tt.input, expected, err)
+		}
+	}
+}
+
+func TestClosures(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input: "fn(a) { fn(b) { a + b } }",
+			expectedConstants: []interface{}{
+				[]Instructions{
+					Make(OpGetFree, 0),
+					Make(OpGetLocal, 0),
+					Make(OpAdd),
+					Make(OpReturnValue),
+				},
+				[]Instructions{
+					Make(OpGetLocal, 0),
+					Make(OpClosure, 0, 1),
+					Make(OpReturnValue),
+				},
+			},
+			expectedInstructions: []Instructions{
+				Make(OpClosure, 1, 0),
+				Make(OpPop),
+			},
+		},
+		{
+			input: "let countDown = fn(x) { countDown(x - 1); };",
+			expectedConstants: []interface{}{
+				1,
+				[]Instructions{
+					Make(OpCurrentClosure),
+					Make(OpGetLocal, 0),
+					Make(OpConstant, 0),
+					Make(OpSub),
+					Make(OpCall, 1),
+					Make(OpReturnValue),
+				},
+			},
+			expectedInstructions: []Instructions{
+				Make(OpClosure, 1, 0),
+				Make(OpSetGlobal, 0),
+				Make(OpNull),
+				Make(OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestUnboundIdentifierIsDeclaredGlobal(t *testing.T) {
+	program := parse("foobar")
+
+	c := New()
+	if err := c.Compile(program); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	bytecode := c.Bytecode()
+	if len(bytecode.GlobalNames) != 1 || bytecode.GlobalNames[0] != "foobar" {
+		t.Errorf("wrong global names. Got=%v", bytecode.GlobalNames)
+	}
+}
+
+func runCompilerTests(t *testing.T, tests []compilerTestCase) {
+	t.Helper()
+
+	for _, tt := range tests {
+		program := parse(tt.input)
+
+		c := New()
+		if err := c.Compile(program); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		bytecode := c.Bytecode()
+
+		testInstructions(t, tt.expectedInstructions, bytecode.Instructions)
+		testConstants(t, tt.expectedConstants, bytecode.Constants)
+	}
+}
+
+func parse(input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l)
+	return p.ParseProgram()
+}
+
+func testInstructions(t *testing.T, expected []Instructions, actual Instructions) {
+	t.Helper()
+
+	concatted := Instructions{}
+	for _, ins := range expected {
+		concatted = append(concatted, ins...)
+	}
+
+	if concatted.String() != actual.String() {
+		t.Errorf("wrong instructions.\nExpected=\n%s\nGot=\n%s", concatted, actual)
+	}
+}
+
+func testConstants(t *testing.T, expected []interface{}, actual []object.Object) {
+	t.Helper()
+
+	if len(expected) != len(actual) {
+		t.Fatalf("wrong number of constants. Expected=%d, got=%d", len(expected), len(actual))
+	}
+
+	for i, constant := range expected {
+		switch constant := constant.(type) {
+		case int:
+			integer, ok := actual[i].(*object.Integer)
+			if !ok || integer.Value != int64(constant) {
+				t.Errorf("constant %d is not Integer %d. Got=%T (%+v)", i, constant, actual[i], actual[i])
+			}
+
+		case []Instructions:
+			fn, ok := actual[i].(*object.CompiledFunction)
+			if !ok {
+				t.Errorf("constant %d is not a CompiledFunction. Got=%T", i, actual[i])
+				continue
+			}
+			testInstructions(t, constant, fn.Instructions)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package compiler
+
+type SymbolScope string
+
+const (
+	GlobalScope   SymbolScope = "GLOBAL"
+	LocalScope    SymbolScope = "LOCAL"
+	FreeScope     SymbolScope = "FREE"
+	FunctionScope SymbolScope = "FUNCTION"
+)
+
+type Symbol struct {
+	Name     string
+	Scope    SymbolScope
+	Index    int
+	Constant bool
+}
+
+// Symbol a definition in a block hides, restored once the block is left.
+type hiddenSymbol struct {
+	symbol  Symbol
+	defined bool
+}
+
+type SymbolTable struct {
+	Outer *SymbolTable
+
+	store          map[string]Symbol
+	numDefinitions int
+
+	// Names of the slots defined in the table, indexed by slot.
+	names []string
+
+	// Blocks entered in the table, innermost last, each with the symbols its definitions hide.
+	blocks []map[string]hiddenSymbol
+
+	// Free symbols captured from the enclosing scopes, in capture order.
+	FreeSymbols []Symbol
+
+	// Names functions compiled within the table refer to as bound outside of themselves, each with the number of blocks
+	// entered when the innermost of them was compiled. As closures capture values, binding one of them afterwards is rejected.
+	referenced map[string]int
+}
+
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{
+		store:       make(map[string]Symbol),
+		FreeSymbols: []Symbol{},
+	}
+}
+
+func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
+	s := NewSymbolTable()
+	s.Outer = outer
+	return s
+}
+
+// Defines a new symbol in the table. Redefining a name already bound in the same scope reuses its slot, as a second let on the same name overwrites the first one.
+// A name first defined in a block gets a slot of its own, hiding any outer binding until the block is left.
+func (s *SymbolTable) Define(name string) Symbol {
+	return s.define(name, false)
+}
+
+// Same as above, for a constant.
+func (s *SymbolTable) DefineConstant(name string) Symbol {
+	return s.define(name, true)
+}
+
+func (s *SymbolTable) define(name string, constant bool) Symbol {
+	if len(s.blocks) > 0 {
+		block := s.blocks[len(s.blocks)-1]
+		if _, ok := block[name]; !ok {
+			previous, defined := s.store[name]
+			block[name] = hiddenSymbol{symbol: previous, defined: defined}
+			return s.add(name, constant)
+		}
+	}
+
+	if symbol, ok := s.store[name]; ok && (symbol.Scope == GlobalScope || symbol.Scope == LocalScope) {
+		symbol.Constant = constant
+		s.store[name] = symbol
+		return symbol
+	}
+
+	return s.add(name, constant)
+}
+
+// Defines the name in a new slot.
+func (s *SymbolTable) add(name string, constant bool) Symbol {
+	symbol := Symbol{Name: name, Index: s.numDefinitions, Constant: constant}
+	if s.Outer == nil {
+		symbol.Scope = GlobalScope
+	} else {
+		symbol.Scope = LocalScope
+	}
+
+	s.store[name] = symbol
+	s.names = append(s.names, name)
+	s.numDefinitions++
+	return symbol
+}
+
+// Whether the name is bound as a constant in the innermost scope of the table, the current block if any.
+func (s *SymbolTable) DefinedConstant(name string) bool {
+	symbol, ok := s.store[name]
+	if !ok || !symbol.Constant {
+		return false
+	}
+
+	if len(s.blocks) == 0 {
+		return true
+	}
+
+	_, ok = s.blocks[len(s.blocks)-1][name]
+	return ok
+}
+
+func (s *SymbolTable) EnterBlock() {
+	s.blocks = append(s.blocks, make(map[string]hiddenSymbol))
+}
+
+// Leaves the innermost block, its definitions going out of scope.
+func (s *SymbolTable) LeaveBlock() {
+	block := s.blocks[len(s.blocks)-1]
+	s.blocks = s.blocks[:len(s.blocks)-1]
+
+	for name, hidden := range block {
+		if hidden.defined {
+			s.store[name] = hidden.symbol
+		} else {
+			delete(s.store, name)
+		}
+	}
+
+	// Functions compiled in the block may still be called once it is left.
+	for name, depth := range s.referenced {
+		s.referenced[name] = min(depth, len(s.blocks))
+	}
+}
+
+// Defines the name of the function being compiled, so that it can refer to itself.
+func (s *SymbolTable) DefineFunctionName(name string) Symbol {
+	symbol := Symbol{Name: name, Index: 0, Scope: FunctionScope}
+	s.store[name] = symbol
+	return symbol
+}
+
+func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
+	symbol, ok := s.store[name]
+	if ok || s.Outer == nil {
+		return symbol, ok
+	}
+
+	symbol, ok = s.Outer.Resolve(name)
+	s.Outer.reference(name)
+	if !ok {
+		return symbol, ok
+	}
+
+	if symbol.Scope == GlobalScope {
+		return symbol, ok
+	}
+
+	return s.defineFree(symbol), true
+}
+
+// Records that a function compiled within the table refers to the name, bound in the table or further out.
+// Globals are left out, as they are shared rather than captured.
+func (s *SymbolTable) reference(name string) {
+	if s.Outer == nil {
+		return
+	}
+
+	if s.referenced == nil {
+		s.referenced = make(map[string]int)
+	}
+	if depth, ok := s.referenced[name]; !ok || depth < len(s.blocks) {
+		s.referenced[name] = len(s.blocks)
+	}
+}
+
+// Whether binding the name now would change what a function compiled before refers to.
+// The evaluator looks bindings up when the function runs, while the function captured them as it was created.
+// A name first defined in a block is only seen by the functions compiled in that block.
+func (s *SymbolTable) Rebinds(name string) bool {
+	depth, ok := s.referenced[name]
+	if !ok {
+		return false
+	}
+
+	if len(s.blocks) > 0 {
+		if _, defined := s.blocks[len(s.blocks)-1][name]; !defined {
+			return depth >= len(s.blocks)
+		}
+	}
+
+	return true
+}
+
+// Resolves the name, and if it is bound nowhere, defines it as a global to be set later on.
+// The evaluator looks identifiers up at runtime, so referring to a global defined further down is legal.
+// The global is defined outside of any block, as it is not bound by one.
+func (s *SymbolTable) ResolveOrDeclare(name string) Symbol {
+	if symbol, ok := s.Resolve(name); ok {
+		return symbol
+	}
+
+	global := s
+	for global.Outer != nil {
+		global = global.Outer
+	}
+
+	return global.add(name, false)
+}
+
+// Names of the symbols defined in this table, indexed by their slot.
+func (s *SymbolTable) LocalNames() []string {
+	names := make([]string, len(s.names))
+	copy(names, s.names)
+	return names
+}
+
+func (s *SymbolTable) defineFree(original Symbol) Symbol {
+	s.FreeSymbols = append(s.FreeSymbols, original)
+
+	symbol := Symbol{Name: original.Name, Index: len(s.FreeSymbols) - 1, Scope: FreeScope}
+	s.store[original.Name] = symbol
+	return symbol
+}
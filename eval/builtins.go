@@ -0,0 +1,55 @@
+package eval
+
+import (
+	"strings"
+
+	"github.com/MohamTahaB/interpreter-go/object"
+)
+
+// Modules implemented by the interpreter, imported by their bare name, ahead of any file of the same name.
+var BUILTIN_MODULES = map[string]*object.Module{
+	"strings": object.NewBuiltinModule("strings", STRINGS_BUILTINS...),
+	"math":    object.NewBuiltinModule("math", MATH_BUILTINS...),
+	"json":    object.NewBuiltinModule("json", JSON_BUILTINS...),
+	"re":      object.NewBuiltinModule("re", RE_BUILTINS...),
+}
+
+// Functions available everywhere without an import, unless a binding of the same name shadows them.
+// Filled in by init, as they call back into the evaluator, which looks them up.
+var BUILTINS = map[string]*object.Builtin{}
+
+func init() {
+	for _, builtins := range [][]*object.Builtin{FUNCTIONAL_BUILTINS, FORMATTING_BUILTINS, OUTPUT_BUILTINS} {
+		for _, builtin := range builtins {
+			BUILTINS[builtin.Name] = builtin
+		}
+	}
+}
+
+// Returns the builtin module an import path names, if it is a bare name.
+func lookupBuiltinModule(path string) (*object.Module, bool) {
+	if strings.ContainsAny(path, "./\\") {
+		return nil, false
+	}
+
+	module, ok := BUILTIN_MODULES[path]
+	return module, ok
+}
+
+// Checks the arguments of a builtin against the types of its parameters, the optional ones last. ANY_OBJ accepts any type.
+func checkArgs(name string, args []object.Object, required int, types ...object.ObjectType) *object.Error {
+	switch {
+	case required == len(types) && len(args) != required:
+		return newError(WRONG_ARGS_NB, required, len(args))
+	case len(args) < required || len(args) > len(types):
+		return newError(WRONG_ARGS_RANGE, required, len(types), len(args))
+	}
+
+	for idx, arg := range args {
+		if types[idx] != object.ANY_OBJ && arg.Type() != types[idx] {
+			return newError(WRONG_ARG_TYPE, idx+1, name, types[idx], arg.Type())
+		}
+	}
+
+	return nil
+}
@@ -1,40 +1,152 @@
 package eval
 
 import (
-	"fmt"
-
 	"github.com/MohamTahaB/interpreter-go/ast"
 	"github.com/MohamTahaB/interpreter-go/object"
 	"github.com/MohamTahaB/interpreter-go/token"
 )
 
 const (
-	UNKNOWN_OP_PREFIX_MSG   = "unknown operator: %s%s"
-	UNKNOWN_OP_INFIX_MSG    = "unknown operator: %s %s %s"
-	TYPE_MISMATCH_INFIX_MSG = "type mismatch: %s %s %s"
-	DIVISION_BY_ZERO        = "division by 0"
+	UNKNOWN_OP_PREFIX_MSG   = object.UNKNOWN_OP_PREFIX_MSG
+	UNKNOWN_OP_INFIX_MSG    = object.UNKNOWN_OP_INFIX_MSG
+	TYPE_MISMATCH_INFIX_MSG = object.TYPE_MISMATCH_INFIX_MSG
+	DIVISION_BY_ZERO        = object.DIVISION_BY_ZERO
+	NEGATIVE_REPEAT_COUNT   = object.NEGATIVE_REPEAT_COUNT
 	IDENT_NOT_FOUND         = "identifier not found: %s"
 	NOT_A_FUNC              = "not a function: %s"
+	NOT_A_STRUCT            = "not a struct: %s"
+	UNKNOWN_FIELD           = "unknown field %s of struct %s"
+	UNKNOWN_MEMBER          = "unknown member %s of %s"
+	NOT_ASSIGNABLE          = "cannot assign member %s of %s"
+	NOT_A_CLASS             = "superclass must be a class: %s"
+	WRONG_ARGS_NB           = "wrong number of arguments: want=%d, got=%d"
+	UNUSABLE_HASH_KEY       = "unusable as hash key: %s"
+	INDEX_NOT_SUPPORTED     = "index operator not supported: %s[%s]"
+	NO_MATCH                = "no match arm for %s"
+	PATTERN_MISMATCH        = "%s does not match pattern %s"
+	NOT_AN_ARRAY            = "cannot destructure %s as an array"
+	NOT_A_HASH              = "cannot destructure %s as a hash"
+	WRONG_ELEMENTS_NB       = "wrong number of elements: want=%d, got=%d"
+	TOO_FEW_ELEMENTS        = "too few elements: want at least %d, got=%d"
+	MISSING_KEY             = "missing key %s"
+	CONSTANT_ASSIGNED       = "cannot assign to constant %s"
+	CONSTANT_REDECLARED     = "cannot redeclare constant %s"
+	MODULE_NOT_FOUND        = "module not found: %s"
+	MODULE_UNREADABLE       = "cannot read module %s: %s"
+	MODULE_PARSE_ERRORS     = "cannot parse module %s: %s"
+	IMPORT_CYCLE            = "import cycle: %s"
+	IMPORTS_UNAVAILABLE     = "imports are only available within a module"
+	EXPORT_NOT_TOP_LEVEL    = "export is only allowed at the top level of a module"
+	WRONG_ARGS_RANGE        = "wrong number of arguments: want=%d to %d, got=%d"
+	WRONG_ARG_TYPE          = "argument %d to %s must be %s, got %s"
+	WRONG_ELEMENT_TYPE      = "element %d of argument %d to %s must be %s, got %s"
+	NEGATIVE_LENGTH         = "negative length: %d"
+	MISSING_FORMAT_ARG      = "missing argument %d for format string"
+	UNMATCHED_FORMAT_BRACE  = "unmatched %s in format string"
+	INVALID_FORMAT_FIELD    = "invalid format field {%s}"
+	NEGATIVE_EXPONENT       = "negative exponent: %s"
+	POWER_TOO_LARGE         = "power too large: %s to the %s"
+	NEGATIVE_SQRT           = "square root of negative number: %s"
+	EMPTY_ARRAY             = "%s of an empty array"
+	ZERO_STEP               = "range step cannot be zero"
+	RANGE_TOO_LARGE         = "range too large: %d elements"
+	INVALID_JSON            = "invalid json: %s"
+	JSON_NOT_INTEGER        = "json number %s is not an integer"
+	NOT_SERIALIZABLE        = "cannot serialize %s to json"
+	CYCLIC_VALUE            = "cannot serialize cyclic %s to json"
+	NEGATIVE_INDENT         = "negative indent: %d"
+	INDENT_TOO_LARGE        = "indent too large: %d, at most %d"
+	JSON_KEY_NOT_STRING     = "cannot serialize %s key to json, keys must be strings"
+	PATH_ESCAPES_ROOT       = "path escapes the root directory: %s"
+	ROOT_REMOVAL            = "cannot remove the root directory"
+	FS_ERROR                = "%s: %s"
+	OUTPUT_ERROR            = "cannot write output: %s"
+	UNFINISHED_VERB         = "unfinished verb at the end of format string"
+	UNKNOWN_VERB            = "unknown verb %%%s in format string"
+	WRONG_VERB_ARG          = "%%%s needs %s, got %s"
+	EXTRA_FORMAT_ARGS       = "%d unused arguments for format string"
+	NEGATIVE_DURATION       = "negative duration: %d"
+	DURATION_TOO_LONG       = "duration too long: %d"
+	INVALID_TIME            = "cannot parse %q as a time of layout %q"
+	INVALID_DURATION        = "cannot parse %q as a duration"
+	EMPTY_RANGE             = "empty range from %d to %d"
+	INVALID_REGEX           = "invalid regex /%s/%s: %s"
 )
 
+// Type of the errors raised with each message of the package.
+var ERROR_KINDS = map[string]string{
+	IDENT_NOT_FOUND: object.NAME_ERROR,
+	NOT_A_FUNC:      object.TYPE_ERROR,
+	NOT_A_STRUCT:    object.TYPE_ERROR,
+	NOT_A_CLASS:     object.TYPE_ERROR,
+	UNKNOWN_FIELD:   object.ATTRIBUTE_ERROR,
+	UNKNOWN_MEMBER:  object.ATTRIBUTE_ERROR,
+	NOT_ASSIGNABLE:  object.ATTRIBUTE_ERROR,
+	WRONG_ARGS_NB:   object.ARGUMENT_ERROR,
+
+	UNUSABLE_HASH_KEY:   object.TYPE_ERROR,
+	INDEX_NOT_SUPPORTED: object.TYPE_ERROR,
+	NO_MATCH:            object.VALUE_ERROR,
+	PATTERN_MISMATCH:    object.VALUE_ERROR,
+	NOT_AN_ARRAY:        object.TYPE_ERROR,
+	NOT_A_HASH:          object.TYPE_ERROR,
+	WRONG_ELEMENTS_NB:   object.VALUE_ERROR,
+	TOO_FEW_ELEMENTS:    object.VALUE_ERROR,
+	MISSING_KEY:         object.KEY_ERROR,
+
+	CONSTANT_ASSIGNED:   object.TYPE_ERROR,
+	CONSTANT_REDECLARED: object.TYPE_ERROR,
+
+	MODULE_NOT_FOUND:     object.IMPORT_ERROR,
+	MODULE_UNREADABLE:    object.IMPORT_ERROR,
+	MODULE_PARSE_ERRORS:  object.IMPORT_ERROR,
+	IMPORT_CYCLE:         object.IMPORT_ERROR,
+	IMPORTS_UNAVAILABLE:  object.IMPORT_ERROR,
+	EXPORT_NOT_TOP_LEVEL: object.IMPORT_ERROR,
+
+	WRONG_ARGS_RANGE:       object.ARGUMENT_ERROR,
+	WRONG_ARG_TYPE:         object.TYPE_ERROR,
+	WRONG_ELEMENT_TYPE:     object.TYPE_ERROR,
+	NEGATIVE_LENGTH:        object.VALUE_ERROR,
+	MISSING_FORMAT_ARG:     object.VALUE_ERROR,
+	UNMATCHED_FORMAT_BRACE: object.VALUE_ERROR,
+	INVALID_FORMAT_FIELD:   object.VALUE_ERROR,
+	NEGATIVE_EXPONENT:      object.VALUE_ERROR,
+	POWER_TOO_LARGE:        object.VALUE_ERROR,
+	NEGATIVE_SQRT:          object.VALUE_ERROR,
+	EMPTY_ARRAY:            object.VALUE_ERROR,
+	ZERO_STEP:              object.VALUE_ERROR,
+	RANGE_TOO_LARGE:        object.VALUE_ERROR,
+	INVALID_JSON:           object.VALUE_ERROR,
+	JSON_NOT_INTEGER:       object.VALUE_ERROR,
+	NOT_SERIALIZABLE:       object.TYPE_ERROR,
+	CYCLIC_VALUE:           object.VALUE_ERROR,
+	NEGATIVE_INDENT:        object.VALUE_ERROR,
+	INDENT_TOO_LARGE:       object.VALUE_ERROR,
+	JSON_KEY_NOT_STRING:    object.TYPE_ERROR,
+	PATH_ESCAPES_ROOT:      object.IO_ERROR,
+	ROOT_REMOVAL:           object.IO_ERROR,
+	FS_ERROR:               object.IO_ERROR,
+	OUTPUT_ERROR:           object.IO_ERROR,
+	UNFINISHED_VERB:        object.VALUE_ERROR,
+	UNKNOWN_VERB:           object.VALUE_ERROR,
+	WRONG_VERB_ARG:         object.TYPE_ERROR,
+	EXTRA_FORMAT_ARGS:      object.VALUE_ERROR,
+	NEGATIVE_DURATION:      object.VALUE_ERROR,
+	DURATION_TOO_LONG:      object.VALUE_ERROR,
+	INVALID_TIME:           object.VALUE_ERROR,
+	INVALID_DURATION:       object.VALUE_ERROR,
+	EMPTY_RANGE:            object.VALUE_ERROR,
+	INVALID_REGEX:          object.VALUE_ERROR,
+}
+
+// Name of the constructor method of classes.
+const INIT_METHOD = "init"
+
 var (
-	NULL  = &object.Null{}
-	TRUE  = &object.Boolean{Value: true}
-	FALSE = &object.Boolean{Value: false}
-
-	INFIX_OPERATORS_FUNCS = map[string]func(object.Object, object.Object) object.Object{
-		token.PLUS:  infixPlus,
-		token.MINUS: infixMinus,
-		token.TIMES: infixTimes,
-		token.SLASH: infixSlash,
-
-		token.EQ:  infixEQ,
-		token.NEQ: infixNEQ,
-		token.LT:  infixLT,
-		token.LEQ: infixLEQ,
-		token.GT:  infixGT,
-		token.GEQ: infixGEQ,
-	}
+	NULL  = object.NULL
+	TRUE  = object.TRUE
+	FALSE = object.FALSE
 )
 
 func Eval(node ast.Node, env *object.Environment) object.Object {
@@ -48,9 +160,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 	// Expressions
 	case *ast.IntegerLiteral:
-		return &object.Integer{
-			Value: node.Value,
-		}
+		return object.NewInteger(node.Value)
 
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
@@ -74,26 +184,89 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(right) {
 			return right
 		}
-		return evalInfixExpression(left, right, node.Operator)
+		return evalInfixExpression(left, right, node.Operator, node.Token)
 
+	// A block has its own bindings, which its enclosing block does not see.
 	case *ast.BlockStatement:
-		return evalBlockStatement(node.Statements, env)
+		return evalBlockStatement(node.Statements, object.NewEnclosedEnvironment(env))
 
 	case *ast.LetStatement:
 		val := Eval(node.Value, env)
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+
+		if err := bindIdentifier(node.Name, val, env, node.Constant()); err != nil {
+			return err
+		}
+
+	case *ast.LetPatternStatement:
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+
+		if err := bindPattern(node.Pattern, val, env, node.Constant()); err != nil {
+			return err
+		}
+
+	case *ast.StructStatement:
+		fields := []string{}
+		for _, field := range node.Fields {
+			fields = append(fields, field.Value)
+		}
+		if err := bindIdentifier(node.Name, &object.Struct{Name: node.Name.Value, Fields: fields}, env, false); err != nil {
+			return err
+		}
+
+	case *ast.ClassStatement:
+		class := evalClassStatement(node, env)
+		if isError(class) {
+			return class
+		}
+		if err := bindIdentifier(node.Name, class, env, false); err != nil {
+			return err
+		}
 
 	case *ast.IfExpression:
 		return evalConditionalExpression(node, env)
 
 	case *ast.ReturnStatement:
+		val := Eval(node.ReturnValue, env)
+		if isError(val) {
+			return val
+		}
 		return &object.ReturnValue{
-			Value: Eval(node.ReturnValue, env),
+			Value: val,
+		}
+
+	case *ast.ThrowStatement:
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
 		}
 
+		// Throwing a caught error raises it again, as is.
+		if exception, ok := val.(*object.Exception); ok {
+			return exception.Error
+		}
+		return object.NewThrownError(val)
+
+	case *ast.TryStatement:
+		return evalTryStatement(node, env)
+
+	case *ast.ImportStatement:
+		if err := evalImportStatement(node, env); err != nil {
+			return err
+		}
+
+	case *ast.ExportStatement:
+		return evalExportStatement(node, env)
+
+	// Bound when the enclosing block is entered, see hoistFunctions. Evaluates to null, as a let does.
+	case *ast.FunctionStatement:
+		return NULL
+
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 
@@ -101,9 +274,11 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		params := node.Parameters
 		body := node.Body
 		return &object.Function{
+			Name:       node.Name,
 			Parameters: params,
 			Env:        env,
 			Body:       body,
+			FrameSize:  node.FrameSize,
 		}
 
 	case *ast.CallExpression:
@@ -117,10 +292,56 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return args[0]
 		}
 
-		return applyFunction(fn, args)
+		// Left for the caller's applyFunction to make, in place of a nested one.
+		if node.Tail {
+			return &object.TailCall{Function: fn, Arguments: args, Call: node.Token}
+		}
+
+		return applyFunction(fn, args, node.Token)
 
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
+
+	case *ast.RegexLiteral:
+		return evalRegexLiteral(node)
+
+	case *ast.StructLiteral:
+		return evalStructLiteral(node, env)
+
+	case *ast.MemberExpression:
+		obj := Eval(node.Object, env)
+		if isError(obj) {
+			return obj
+		}
+		return evalMemberExpression(obj, node.Property.Value)
+
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
+
+	case *ast.ArrayLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
+		}
+		return &object.Array{Elements: elements}
+
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+
+	case *ast.IndexExpression:
+		left := Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+
+		index := Eval(node.Index, env)
+		if isError(index) {
+			return index
+		}
+		return evalIndexExpression(left, index)
+
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
 	}
 
 	return NULL
@@ -129,6 +350,10 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 func evalProgram(statements []ast.Statement, env *object.Environment) object.Object {
 	var result object.Object
 
+	if err := hoistFunctions(statements, env); err != nil {
+		return err
+	}
+
 	for _, statement := range statements {
 		result = Eval(statement, env)
 
@@ -146,6 +371,10 @@ func evalProgram(statements []ast.Statement, env *object.Environment) object.Obj
 func evalBlockStatement(statements []ast.Statement, env *object.Environment) object.Object {
 	var result object.Object
 
+	if err := hoistFunctions(statements, env); err != nil {
+		return err
+	}
+
 	for _, statement := range statements {
 		result = Eval(statement, env)
 
@@ -160,28 +389,93 @@ func evalBlockStatement(statements []ast.Statement, env *object.Environment) obj
 	return result
 }
 
-func evalPrefixExpression(op string, right object.Object) object.Object {
-	switch op {
+// Binds the functions declared in the statements before running any of them, so that they can be called from anywhere in the list.
+func hoistFunctions(statements []ast.Statement, env *object.Environment) *object.Error {
+	for _, statement := range statements {
+		if export, ok := statement.(*ast.ExportStatement); ok {
+			statement = export.Declaration
+		}
 
-	case token.NEG:
-		return evalNegationPrefixExpression(right)
-	case token.MINUS:
-		return evalNegativePrefixExpression(right)
+		if declaration, ok := statement.(*ast.FunctionStatement); ok {
+			if err := bindIdentifier(declaration.Name, Eval(declaration.Function, env), env, false); err != nil {
+				return err
+			}
+		}
+	}
 
-	default:
-		return newError(UNKNOWN_OP_PREFIX_MSG, op, right.Type())
+	return nil
+}
+
+// Operators are dispatched on the types of their operands by the object package's registry.
+func evalPrefixExpression(op string, right object.Object) object.Object {
+	return object.ApplyPrefix(op, right)
+}
+
+func evalInfixExpression(l, r object.Object, operator string, tok token.Token) object.Object {
+	if _, ok := object.LookupInfix(operator, l.Type(), r.Type()); !ok {
+		if result, ok := applyOperatorMethod(operator, l, r, tok); ok {
+			return result
+		}
 	}
+
+	return object.ApplyInfix(operator, l, r)
 }
 
-func evalInfixExpression(l, r object.Object, operator string) object.Object {
+// Applies the special method a user defined type overloads the operator with, if any.
+// Without __ne__, != negates __eq__. The method is called at the position of the operator.
+func applyOperatorMethod(operator string, l, r object.Object, tok token.Token) (object.Object, bool) {
+	receiver, arg := l, r
+	if operator == token.IN {
+		receiver, arg = r, l
+	}
+
+	overloadable, ok := receiver.(object.Overloadable)
+	if !ok {
+		return nil, false
+	}
+
+	if method, ok := overloadable.GetMethod(object.OPERATOR_METHODS[operator]); ok {
+		return applyFunction(method, []object.Object{arg}, tok), true
+	}
+
+	if operator != token.NEQ {
+		return nil, false
+	}
 
-	infixOp, ok := INFIX_OPERATORS_FUNCS[operator]
+	method, ok := overloadable.GetMethod(object.OPERATOR_METHODS[token.EQ])
 	if !ok {
-		// What is not recognized is the operator
-		return newError(UNKNOWN_OP_INFIX_MSG, "", operator, "")
+		return nil, false
+	}
+
+	result := applyFunction(method, []object.Object{arg}, tok)
+	if isError(result) {
+		return result, true
+	}
+	return nativeBoolToBooleanObject(!result.Truthy()), true
+}
+
+// Evaluates the try block, then the catch block if the try block raised an error, and the finally block whatever happened.
+// A return or an error from the finally block takes precedence over the outcome of the others.
+// The catch parameter is only bound in the catch block.
+func evalTryStatement(node *ast.TryStatement, env *object.Environment) object.Object {
+	result := Eval(node.Block, env)
+
+	if errObj, ok := result.(*object.Error); ok && node.CatchBlock != nil {
+		catchEnv := object.NewEnclosedEnvironment(env)
+		bindIdentifier(node.CatchParameter, &object.Exception{Error: errObj}, catchEnv, false)
+		result = evalBlockStatement(node.CatchBlock.Statements, catchEnv)
 	}
 
-	return infixOp(l, r)
+	if node.FinallyBlock != nil {
+		finallyResult := Eval(node.FinallyBlock, env)
+
+		switch finallyResult.(type) {
+		case *object.Error, *object.ReturnValue:
+			return finallyResult
+		}
+	}
+
+	return result
 }
 
 func evalConditionalExpression(conditionalExp *ast.IfExpression, env *object.Environment) object.Object {
@@ -203,290 +497,460 @@ func evalConditionalExpression(conditionalExp *ast.IfExpression, env *object.Env
 }
 
 func evalIdentifier(ident *ast.Identifier, env *object.Environment) object.Object {
-	obj, ok := env.Get(ident.Value)
-	if ok {
+	if obj, ok := lookupIdentifier(ident, env); ok {
 		return obj
 	}
 
+	if builtin, ok := BUILTINS[ident.Value]; ok {
+		return builtin
+	}
+
 	return newError(IDENT_NOT_FOUND, ident.Value)
 }
 
-func evalNegationPrefixExpression(right object.Object) object.Object {
-	var nativeRightEval bool
+// Returns the value bound to the identifier. While its resolved binding is not made yet, the enclosing ones are looked up instead, as they would be by name.
+func lookupIdentifier(ident *ast.Identifier, env *object.Environment) (object.Object, bool) {
+	name := ident.Value
+	for ; ident != nil && ident.Resolved; ident = ident.Fallback {
+		if obj, ok := env.GetAt(ident.Depth, ident.Slot); ok {
+			return obj, true
+		}
+	}
 
-	if right == NULL {
-		return newError(UNKNOWN_OP_PREFIX_MSG, token.NEG, right.Type())
+	return env.Get(name)
+}
+
+// Constructs a struct instance, fields left out of the literal being null.
+func evalStructLiteral(lit *ast.StructLiteral, env *object.Environment) object.Object {
+	structObj := Eval(lit.Struct, env)
+	if isError(structObj) {
+		return structObj
 	}
 
-	// Native prefix eval to be determined
-	switch prefixEval := right.(type) {
-	case *object.Boolean:
-		nativeRightEval = booleanObjectToNativeBool(prefixEval)
-	case *object.Integer:
-		nativeRightEval = prefixEval.Value != 0
+	s, ok := structObj.(*object.Struct)
+	if !ok {
+		return newError(NOT_A_STRUCT, structObj.Type())
 	}
-	return nativeBoolToBooleanObject(!nativeRightEval)
-}
 
-func evalNegativePrefixExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
-		return newError(UNKNOWN_OP_PREFIX_MSG, token.MINUS, right.Type())
+	instance := &object.StructInstance{Struct: s, Fields: make(map[string]object.Object, len(s.Fields))}
+	for _, field := range s.Fields {
+		instance.Fields[field] = NULL
 	}
 
-	val := right.(*object.Integer).Value
-	return &object.Integer{Value: -val}
+	for idx, name := range lit.Names {
+		if !s.HasField(name.Value) {
+			return newError(UNKNOWN_FIELD, name.Value, s.Name)
+		}
+
+		val := Eval(lit.Values[idx], env)
+		if isError(val) {
+			return val
+		}
+		instance.Fields[name.Value] = val
+	}
+
+	return instance
 }
 
-func evalExpressions(args []ast.Expression, env *object.Environment) []object.Object {
-	argsEval := []object.Object{}
+func evalClassStatement(node *ast.ClassStatement, env *object.Environment) object.Object {
+	class := &object.Class{Name: node.Name.Value, Methods: make(map[string]*object.Function, len(node.Methods))}
 
-	for _, arg := range args {
-		argEval := Eval(arg, env)
+	if node.Superclass != nil {
+		superclass := evalIdentifier(node.Superclass, env)
+		if isError(superclass) {
+			return superclass
+		}
 
-		if isError(argEval) {
-			return []object.Object{argEval}
+		var ok bool
+		if class.Superclass, ok = superclass.(*object.Class); !ok {
+			return newError(NOT_A_CLASS, superclass.Type())
 		}
+	}
 
-		argsEval = append(argsEval, argEval)
+	for idx, method := range node.Methods {
+		class.Methods[node.MethodNames[idx].Value] = &object.Function{
+			Name:       method.Name,
+			Parameters: method.Parameters,
+			Env:        env,
+			Body:       method.Body,
+			FrameSize:  method.FrameSize,
+		}
 	}
 
-	return argsEval
+	return class
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+// Creates an instance of the class, and runs its constructor, the init method, on the arguments.
+func instantiate(class *object.Class, args []object.Object, call token.Token) object.Object {
+	instance := &object.Instance{Class: class, Fields: make(map[string]object.Object)}
 
-	function, ok := fn.(*object.Function)
+	init, ok := instance.GetMethod(INIT_METHOD)
 	if !ok {
-		return newError(NOT_A_FUNC, fn.Type())
+		if len(args) != 0 {
+			return newError(WRONG_ARGS_NB, 0, len(args))
+		}
+		return instance
 	}
 
-	extendedEnv := extendedFunctionEnv(function, args)
-	evaluated := Eval(function.Body, extendedEnv)
+	if result := applyFunction(init, args, call); isError(result) {
+		return result
+	}
 
-	return unwrapReturnValue(evaluated)
+	return instance
 }
 
-func extendedFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
-	env := object.NewEnclosedEnvironment(fn.Env)
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
 
-	for idx, param := range fn.Parameters {
-		env.Set(param.Value, args[idx])
+	for idx, keyNode := range node.Keys {
+		key := Eval(keyNode, env)
+		if isError(key) {
+			return key
+		}
+
+		hashable, ok := key.(object.Hashable)
+		if !ok {
+			return newError(UNUSABLE_HASH_KEY, key.Type())
+		}
+
+		value := Eval(node.Values[idx], env)
+		if isError(value) {
+			return value
+		}
+
+		hash.Set(hashable, value)
 	}
 
-	return env
+	return hash
 }
 
-func unwrapReturnValue(val object.Object) object.Object {
-	returnVal, ok := val.(*object.ReturnValue)
-	if ok {
-		return returnVal.Value
+// Indexes an array by position, or a hash by key. Out of range positions and missing keys give null.
+func evalIndexExpression(left, index object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		idx, ok := index.(*object.Integer)
+		if !ok {
+			break
+		}
+
+		if idx.Value < 0 || idx.Value >= int64(len(left.Elements)) {
+			return NULL
+		}
+		return left.Elements[idx.Value]
+
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError(UNUSABLE_HASH_KEY, index.Type())
+		}
+
+		if value, ok := left.Get(key); ok {
+			return value
+		}
+		return NULL
 	}
 
-	return val
+	return newError(INDEX_NOT_SUPPORTED, left.Type(), index.Type())
 }
 
-func nativeBoolToBooleanObject(input bool) *object.Boolean {
-	if input {
-		return TRUE
+// Evaluates the body of the first arm whose pattern matches the value and whose guard holds, in an environment binding the names of the pattern.
+func evalMatchExpression(node *ast.MatchExpression, env *object.Environment) object.Object {
+	value := Eval(node.Value, env)
+	if isError(value) {
+		return value
 	}
 
-	return FALSE
-}
+	for _, arm := range node.Arms {
+		armEnv := object.NewEnclosedEnvironment(env)
+		if bindPattern(arm.Pattern, value, armEnv, false) != nil {
+			continue
+		}
+
+		if arm.Guard != nil {
+			guard := Eval(arm.Guard, armEnv)
+			if isError(guard) {
+				return guard
+			}
+			if !guard.Truthy() {
+				continue
+			}
+		}
 
-func booleanObjectToNativeBool(input *object.Boolean) bool {
-	return input == TRUE
+		return Eval(arm.Body, armEnv)
+	}
+
+	return newError(NO_MATCH, value.Inspect())
 }
 
-func infixPlus(l, r object.Object) object.Object {
+// Binds the names of the pattern to the parts of the value they stand for, or returns why the value does not fit the pattern.
+func bindPattern(pattern ast.Expression, value object.Object, env *object.Environment, constant bool) *object.Error {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != ast.WILDCARD {
+			return bindIdentifier(pattern, value, env, constant)
+		}
+		return nil
+
+	case *ast.ArrayPattern:
+		return bindArrayPattern(pattern, value, env, constant)
 
-	// Mismatch case
-	if l.Type() != r.Type() {
-		return newError(TYPE_MISMATCH_INFIX_MSG, l.Type(), token.PLUS, r.Type())
+	case *ast.HashPattern:
+		for idx, keyNode := range pattern.Keys {
+			element, err := patternKeyValue(value, Eval(keyNode, env).(object.Hashable))
+			if err != nil {
+				return err
+			}
+
+			if err := bindPattern(pattern.Values[idx], element, env, constant); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
-	// Fetch for the right infix function
-	op, ok := object.OBJECT_INFIX_PLUS_FUNCS[l.Type()]
-	if !ok {
-		return newError(UNKNOWN_OP_INFIX_MSG, l.Type(), token.PLUS, r.Type())
+	// Literals match the values of the same type they are equal to.
+	literal := Eval(pattern, env)
+	if literal.Type() != value.Type() || !object.ApplyInfix(token.EQ, literal, value).Truthy() {
+		return newError(PATTERN_MISMATCH, value.Inspect(), literal.Inspect())
 	}
 
-	return op(l, r)
+	return nil
 }
 
-func infixMinus(l, r object.Object) object.Object {
+func bindArrayPattern(pattern *ast.ArrayPattern, value object.Object, env *object.Environment, constant bool) *object.Error {
+	array, ok := value.(*object.Array)
+	if !ok {
+		return newError(NOT_AN_ARRAY, value.Type())
+	}
+
+	switch {
+	case pattern.Rest == nil && len(array.Elements) != len(pattern.Elements):
+		return newError(WRONG_ELEMENTS_NB, len(pattern.Elements), len(array.Elements))
+	case len(array.Elements) < len(pattern.Elements):
+		return newError(TOO_FEW_ELEMENTS, len(pattern.Elements), len(array.Elements))
+	}
 
-	// Mismatch case
-	if l.Type() != r.Type() {
-		return newError(UNKNOWN_OP_INFIX_MSG, l.Type(), token.MINUS, r.Type())
+	for idx, element := range pattern.Elements {
+		if err := bindPattern(element, array.Elements[idx], env, constant); err != nil {
+			return err
+		}
 	}
 
-	// Fetch for the right infix function
-	op, ok := object.OBJECT_INFIX_MINUS_FUNCS[l.Type()]
-	if !ok {
-		return newError(UNKNOWN_OP_INFIX_MSG, l.Type(), token.MINUS, r.Type())
+	if pattern.Rest == nil {
+		return nil
 	}
 
-	return op(l, r)
+	rest := make([]object.Object, len(array.Elements)-len(pattern.Elements))
+	copy(rest, array.Elements[len(pattern.Elements):])
+	return bindPattern(pattern.Rest, &object.Array{Elements: rest}, env, constant)
 }
 
-func infixTimes(l, r object.Object) object.Object {
-
-	if l.Type() != object.INTEGER_OBJ || r.Type() != object.INTEGER_OBJ {
+// Returns the value of the key in a hash, or the member named by the key of an object with members.
+func patternKeyValue(value object.Object, key object.Hashable) (object.Object, *object.Error) {
+	switch value := value.(type) {
+	case *object.Hash:
+		if element, ok := value.Get(key); ok {
+			return element, nil
+		}
 
-		// Whether a mismatch or unknow op
-		if l.Type() == r.Type() {
-			return newError(UNKNOWN_OP_INFIX_MSG, l.Type(), token.TIMES, r.Type())
+	case object.Members:
+		if name, ok := key.(*object.String); ok {
+			if member, ok := value.GetMember(name.Value); ok {
+				return member, nil
+			}
 		}
 
-		return newError(TYPE_MISMATCH_INFIX_MSG, l.Type(), token.TIMES, r.Type())
+	default:
+		return nil, newError(NOT_A_HASH, value.Type())
 	}
 
-	// At this point it is safe to cast
-	left, right := l.(*object.Integer), r.(*object.Integer)
+	return nil, newError(MISSING_KEY, key.Inspect())
+}
 
-	return &object.Integer{
-		Value: left.Value * right.Value,
+func evalMemberExpression(obj object.Object, name string) object.Object {
+	members, ok := obj.(object.Members)
+	if !ok {
+		return newError(UNKNOWN_MEMBER, name, obj.Type())
 	}
-}
 
-func infixSlash(l, r object.Object) object.Object {
+	member, ok := members.GetMember(name)
+	if !ok {
+		return newError(UNKNOWN_MEMBER, name, obj.Type())
+	}
 
-	if l.Type() != object.INTEGER_OBJ || r.Type() != object.INTEGER_OBJ {
+	return member
+}
 
-		// Whether a mismatch or unknow op
-		if l.Type() == r.Type() {
-			return newError(UNKNOWN_OP_INFIX_MSG, l.Type(), token.SLASH, r.Type())
+// Assigns a binding or a member of an object, and returns the assigned value.
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	if ident, ok := node.Target.(*ast.Identifier); ok {
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
 		}
 
-		return newError(TYPE_MISMATCH_INFIX_MSG, l.Type(), token.SLASH, r.Type())
+		return assignIdentifier(ident, val, env)
 	}
 
-	// At this point it is safe to cast
-	left, right := l.(*object.Integer), r.(*object.Integer)
+	target := node.Target.(*ast.MemberExpression)
 
-	if right.Value == 0 {
-		return newError(DIVISION_BY_ZERO)
+	obj := Eval(target.Object, env)
+	if isError(obj) {
+		return obj
 	}
 
-	return &object.Integer{
-		Value: left.Value / right.Value,
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
 	}
-}
 
-func infixEQ(l, r object.Object) object.Object {
+	members, ok := obj.(object.MutableMembers)
+	if !ok || !members.SetMember(target.Property.Value, val) {
+		return newError(NOT_ASSIGNABLE, target.Property.Value, obj.Type())
+	}
+
+	return val
+}
 
-	if l == NULL || r == NULL {
-		return &object.Boolean{
-			Value: l == r,
+// Assigns a name in the environment it is bound in, which may be an enclosing one. Only names already bound, and not constant, can be assigned.
+func assignIdentifier(ident *ast.Identifier, val object.Object, env *object.Environment) object.Object {
+	// Bindings not made yet are skipped, as in lookupIdentifier.
+	for target := ident; target != nil && target.Resolved; target = target.Fallback {
+		frame := env.Up(target.Depth)
+		if _, ok := frame.GetAt(0, target.Slot); !ok {
+			continue
+		}
+		if frame.IsConstantAt(target.Slot) {
+			return newError(CONSTANT_ASSIGNED, ident.Value)
 		}
+
+		return frame.SetAt(target.Slot, val)
 	}
 
-	if l.Type() != r.Type() {
-		return newError(UNKNOWN_OP_INFIX_MSG, l.Type(), token.EQ, r.Type())
+	owner, ok := env.Owner(ident.Value)
+	if !ok {
+		return newError(IDENT_NOT_FOUND, ident.Value)
+	}
+	if owner.IsConstant(ident.Value) {
+		return newError(CONSTANT_ASSIGNED, ident.Value)
 	}
 
-	switch r.Type() {
-	case object.BOOLEAN_OBJ:
-		rBoolean, lBoolean := r.(*object.Boolean).Value, l.(*object.Boolean).Value
-		return &object.Boolean{
-			Value: lBoolean == rBoolean,
-		}
+	return owner.Set(ident.Value, val)
+}
 
-	case object.INTEGER_OBJ:
-		rInteger, lInteger := r.(*object.Integer).Value, l.(*object.Integer).Value
-		return &object.Boolean{
-			Value: lInteger == rInteger,
+func evalExpressions(args []ast.Expression, env *object.Environment) []object.Object {
+	argsEval := []object.Object{}
+
+	for _, arg := range args {
+		argEval := Eval(arg, env)
+
+		if isError(argEval) {
+			return []object.Object{argEval}
 		}
 
-	default:
-		return newError(UNKNOWN_OP_INFIX_MSG, l.Type(), token.EQ, r.Type())
+		argsEval = append(argsEval, argEval)
 	}
-}
 
-func infixNEQ(l, r object.Object) object.Object {
+	return argsEval
+}
 
-	if l == NULL || r == NULL {
-		return &object.Boolean{
-			Value: l != r,
+// Applies the function, then, as long as it ends on a tail call, the function it calls. The stack stays flat however deep the recursion.
+// An error raised by a call records it in its trace, made at the position of the call token: a tail call replaces the call it ends.
+func applyFunction(fn object.Object, args []object.Object, call token.Token) object.Object {
+	for {
+		// Calling a class constructs an instance of it.
+		if class, ok := fn.(*object.Class); ok {
+			return instantiate(class, args, call)
 		}
-	}
 
-	if l.Type() != r.Type() {
-		return newError(UNKNOWN_OP_INFIX_MSG, l.Type(), token.NEQ, r.Type())
-	}
+		if builtin, ok := fn.(*object.Builtin); ok {
+			return builtin.Fn(args...)
+		}
 
-	switch r.Type() {
-	case object.BOOLEAN_OBJ:
-		rBoolean, lBoolean := r.(*object.Boolean).Value, l.(*object.Boolean).Value
-		return &object.Boolean{
-			Value: lBoolean != rBoolean,
+		function, ok := fn.(*object.Function)
+		if !ok {
+			return newError(NOT_A_FUNC, fn.Type())
 		}
 
-	case object.INTEGER_OBJ:
-		rInteger, lInteger := r.(*object.Integer).Value, l.(*object.Integer).Value
-		return &object.Boolean{
-			Value: lInteger != rInteger,
+		if len(args) != len(function.Parameters) {
+			return newError(WRONG_ARGS_NB, len(function.Parameters), len(args))
 		}
 
-	default:
-		return newError(UNKNOWN_OP_INFIX_MSG, l.Type(), token.NEQ, r.Type())
-	}
-}
+		extendedEnv := extendedFunctionEnv(function, args)
+		// The body is evaluated in the call environment itself, where the parameters are bound.
+		evaluated := unwrapReturnValue(evalBlockStatement(function.Body.Statements, extendedEnv))
 
-func infixLEQ(l, r object.Object) object.Object {
+		if errObj, ok := evaluated.(*object.Error); ok {
+			errObj.AddFrame(function.Name, call)
+			return errObj
+		}
 
-	if l.Type() != object.INTEGER_OBJ || r.Type() != object.INTEGER_OBJ {
-		return newError(UNKNOWN_OP_INFIX_MSG, l.Type(), token.LEQ, r.Type())
-	}
+		tailCall, ok := evaluated.(*object.TailCall)
+		if !ok {
+			return evaluated
+		}
 
-	rInteger, lInteger := r.(*object.Integer).Value, l.(*object.Integer).Value
-	return &object.Boolean{
-		Value: lInteger <= rInteger,
+		fn, args, call = tailCall.Function, tailCall.Arguments, tailCall.Call
 	}
-
 }
 
-func infixLT(l, r object.Object) object.Object {
+// Binds the identifier, in its slot if the resolver gave it one, by name otherwise. A constant cannot be bound again in the same environment.
+func bindIdentifier(ident *ast.Identifier, val object.Object, env *object.Environment, constant bool) *object.Error {
+	if ident.Resolved {
+		if env.IsConstantAt(ident.Slot) {
+			return newError(CONSTANT_REDECLARED, ident.Value)
+		}
 
-	if l.Type() != object.INTEGER_OBJ || r.Type() != object.INTEGER_OBJ {
-		return newError(UNKNOWN_OP_INFIX_MSG, l.Type(), token.LT, r.Type())
+		if constant {
+			env.SetConstantAt(ident.Slot, val)
+		} else {
+			env.SetAt(ident.Slot, val)
+		}
+		return nil
 	}
 
-	rInteger, lInteger := r.(*object.Integer).Value, l.(*object.Integer).Value
-	return &object.Boolean{
-		Value: lInteger < rInteger,
+	if env.IsConstant(ident.Value) {
+		return newError(CONSTANT_REDECLARED, ident.Value)
 	}
 
+	if constant {
+		env.SetConstant(ident.Value, val)
+	} else {
+		env.Set(ident.Value, val)
+	}
+	return nil
 }
 
-func infixGEQ(l, r object.Object) object.Object {
-
-	if l.Type() != object.INTEGER_OBJ || r.Type() != object.INTEGER_OBJ {
-		return newError(UNKNOWN_OP_INFIX_MSG, l.Type(), token.GEQ, r.Type())
-	}
+func extendedFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+	env := object.NewFrameEnvironment(fn.Env, fn.FrameSize)
 
-	rInteger, lInteger := r.(*object.Integer).Value, l.(*object.Integer).Value
-	return &object.Boolean{
-		Value: lInteger >= rInteger,
+	for idx, param := range fn.Parameters {
+		bindIdentifier(param, args[idx], env, false)
 	}
 
+	return env
 }
 
-func infixGT(l, r object.Object) object.Object {
-
-	if l.Type() != object.INTEGER_OBJ || r.Type() != object.INTEGER_OBJ {
-		return newError(UNKNOWN_OP_INFIX_MSG, l.Type(), token.GT, r.Type())
+func unwrapReturnValue(val object.Object) object.Object {
+	returnVal, ok := val.(*object.ReturnValue)
+	if ok {
+		return returnVal.Value
 	}
 
-	rInteger, lInteger := r.(*object.Integer).Value, l.(*object.Integer).Value
-	return &object.Boolean{
-		Value: lInteger > rInteger,
-	}
+	return val
+}
 
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	return object.NewBoolean(input)
 }
 
 func newError(format string, a ...interface{}) *object.Error {
-	return &object.Error{Message: fmt.Sprintf(format, a...)}
+	err := object.NewError(format, a...)
+	if kind, ok := ERROR_KINDS[format]; ok {
+		err.Kind = kind
+	}
+
+	return err
 }
 
 func isError(obj object.Object) bool {
@@ -1,11 +1,18 @@
 package eval
 
 import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/MohamTahaB/interpreter-go/ast"
 	"github.com/MohamTahaB/interpreter-go/lexer"
 	"github.com/MohamTahaB/interpreter-go/object"
 	"github.com/MohamTahaB/interpreter-go/parser"
+	"github.com/MohamTahaB/interpreter-go/resolver"
 )
 
 func TestEvalIntegerExpression(t *testing.T) {
@@ -129,32 +136,32 @@ func TestReturnExpressions(t *testing.T) {
 
 func TestErrorHandling(t *testing.T) {
 	tests := []struct {
-		input           string
-		expectedMessage string
+		input         string
+		expectedError string
 	}{
 		{
 			"5 + true;",
-			"type mismatch: INTEGER + BOOLEAN",
+			"TypeError: type mismatch: INTEGER + BOOLEAN",
 		},
 		{
 			"5 + true; 5;",
-			"type mismatch: INTEGER + BOOLEAN",
+			"TypeError: type mismatch: INTEGER + BOOLEAN",
 		},
 		{
 			"-true;",
-			"unknown operator: -BOOLEAN",
+			"TypeError: unknown operator: -BOOLEAN",
 		},
 		{
 			"true + false;",
-			"unknown operator: BOOLEAN + BOOLEAN",
+			"TypeError: unknown operator: BOOLEAN + BOOLEAN",
 		},
 		{
 			"5; true + false; 5",
-			"unknown operator: BOOLEAN + BOOLEAN",
+			"TypeError: unknown operator: BOOLEAN + BOOLEAN",
 		},
 		{
 			"if (10 > 1) { true + false; }",
-			"unknown operator: BOOLEAN + BOOLEAN",
+			"TypeError: unknown operator: BOOLEAN + BOOLEAN",
 		},
 		{
 			`if (10 > 1) {
@@ -163,30 +170,22 @@ func TestErrorHandling(t *testing.T) {
 				}
 				return 1;
 			}`,
-			"unknown operator: BOOLEAN + BOOLEAN",
+			"TypeError: unknown operator: BOOLEAN + BOOLEAN",
 		},
 		{
 			"foobar",
-			"identifier not found: foobar",
+			"NameError: identifier not found: foobar",
 		},
 		{
 			`"Hello" - "World"`,
-			"unknown operator: STRING - STRING",
+			"TypeError: unknown operator: STRING - STRING",
 		},
 	}
 
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
 
-		errObj, ok := evaluated.(*object.Error)
-		if !ok {
-			t.Errorf("no error object returned. Got=%T(%+v)", evaluated, evaluated)
-			continue
-		}
-
-		if errObj.Message != tt.expectedMessage {
-			t.Errorf("wrong error message. Expected=%q, got=%q", tt.expectedMessage, errObj.Message)
-		}
+		testErrorObject(t, evaluated, tt.expectedError)
 	}
 }
 
@@ -211,12 +210,63 @@ func TestNEGOperator(t *testing.T) {
 	}
 }
 
+func TestInternedObjects(t *testing.T) {
+	booleans := []struct {
+		input    string
+		expected *object.Boolean
+	}{
+		{"1 < 2", TRUE},
+		{"1 > 2", FALSE},
+		{"1 == 1", TRUE},
+		{"true != true", FALSE},
+		{"!(1 < 2)", FALSE},
+		{"!(1 > 2)", TRUE},
+		{"!!(1 == 1)", TRUE},
+	}
+
+	for _, tt := range booleans {
+		evaluated := testEval(tt.input)
+		if evaluated != tt.expected {
+			t.Errorf("input %q: result is not the %s singleton. Got=%T (%+v)", tt.input, tt.expected.Inspect(), evaluated, evaluated)
+		}
+	}
+
+	if testEval("5 + 5") != testEval("10") {
+		t.Errorf("small integers are not shared")
+	}
+
+	if testEval("100000 + 1") == testEval("100001") {
+		t.Errorf("large integers should not be cached")
+	}
+}
+
+func TestComparisonsDoNotAllocate(t *testing.T) {
+	one, two := object.NewInteger(1), object.NewInteger(2)
+
+	for _, op := range []string{"==", "!=", "<", ">", "<=", ">="} {
+		allocs := testing.AllocsPerRun(100, func() {
+			object.ApplyInfix(op, one, two)
+		})
+		if allocs != 0 {
+			t.Errorf("operator %s allocates %v times per run", op, allocs)
+		}
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		object.ApplyInfix("+", one, two)
+	})
+	if allocs != 0 {
+		t.Errorf("small integer addition allocates %v times per run", allocs)
+	}
+}
+
 func testEval(input string) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
 	env := object.NewEnvironment()
 
 	program := p.ParseProgram()
+	resolver.Resolve(program)
 
 	return Eval(program, env)
 }
@@ -299,6 +349,74 @@ func TestFunctionApplication(t *testing.T) {
 	}
 }
 
+func TestArgumentCounts(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"let f = fn(a, b) { a }; f(1)", "ArgumentError: wrong number of arguments: want=2, got=1"},
+		{"let f = fn(a) { a }; f(1, 2)", "ArgumentError: wrong number of arguments: want=1, got=2"},
+		{"fn() { 1 }(1)", "ArgumentError: wrong number of arguments: want=0, got=1"},
+	}
+
+	for _, tt := range tests {
+		testErrorObject(t, testEval(tt.input), tt.expectedError)
+	}
+}
+
+func TestResolvedBindings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } }; fib(10);", 55},
+		{"let f = fn() { let g = fn() { x }; let x = 5; g() }; f();", 5},
+		{"let f = fn(x) { let x = x * 2; x }; f(3);", 6},
+		{"let x = 1; let f = fn() { let y = x + 1; let x = 10; x + y }; f();", 12},
+		{"let f = fn(a) { fn(b) { fn(c) { a + b + c } } }; f(1)(2)(3);", 6},
+		{"let f = fn(a) { let b = 0; if (a > 0) { b = a; } b }; f(4);", 4},
+		{"let x = 1; let f = fn() { let g = fn() { x }; let r = g(); let x = 5; r }; f()", 1},
+		{"let x = 1; let f = fn() { let g = fn() { x }; let a = g(); let x = 5; a * 10 + g() }; f()", 15},
+		{"let h = fn() { let x = 2; let f = fn() { let g = fn() { x }; let r = g(); let x = 5; r }; f() }; h()", 2},
+		{"let x = 1; let f = fn() { let g = fn() { x = 3 }; g(); let x = 5; x }; f() + x", 8},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestTailCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{
+			"let count = fn(n) { if (n == 0) { 0 } else { count(n - 1) } }; count(1000000);",
+			0,
+		},
+		{
+			"let sum = fn(n, acc) { if (n == 0) { return acc; } return sum(n - 1, acc + n); }; sum(100000, 0);",
+			5000050000,
+		},
+		{
+			`let isEven = fn(n) { if (n == 0) { 1 } else { isOdd(n - 1) } };
+			let isOdd = fn(n) { if (n == 0) { 0 } else { isEven(n - 1) } };
+			isEven(100001);`,
+			0,
+		},
+		{
+			// Not a tail call, the result is still added to.
+			"let sum = fn(n) { if (n == 0) { 0 } else { n + sum(n - 1) } }; sum(100);",
+			5050,
+		},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func TestStringConcatenation(t *testing.T) {
 	input := `"hello" + " " + "world"`
 
@@ -313,41 +431,1483 @@ func TestStringConcatenation(t *testing.T) {
 	}
 }
 
-func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
-	result, ok := obj.(*object.Integer)
-	if !ok {
-		t.Errorf("object is not of type Integer. Got=%T (%+v)", obj, obj)
-		return false
+func TestStringComparisons(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`"a" == "a"`, true},
+		{`"a" == "b"`, false},
+		{`"a" != "b"`, true},
+		{`"a" != "a"`, false},
+		{`"abc" < "abd"`, true},
+		{`"ab" < "abc"`, true},
+		{`"b" > "abc"`, true},
+		{`"abc" <= "abc"`, true},
+		{`"abd" <= "abc"`, false},
+		{`"abc" >= "abc"`, true},
+		{`"ab" >= "abc"`, false},
+		{`"ell" in "hello"`, true},
+		{`"" in "hello"`, true},
+		{`"xyz" in "hello"`, false},
+		{`"a" + "b" == "ab"`, true},
 	}
 
-	if result.Value != expected {
-		t.Errorf("object has wrong value. Expect=%d, got=%d", expected, result.Value)
-		return false
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
 	}
+}
 
-	return true
+func TestStringRepetition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"ab" * 3`, "ababab"},
+		{`3 * "ab"`, "ababab"},
+		{`"ab" * 0`, ""},
+		{`"" * 5`, ""},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Errorf("object is not String. Got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
 
+		if str.Value != tt.expected {
+			t.Errorf("String has wrong value. Expected=%q, got=%q", tt.expected, str.Value)
+		}
+	}
 }
 
-func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
-	result, ok := obj.(*object.Boolean)
+func TestStringOperatorErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{`"a" == 1`, "TypeError: type mismatch: STRING == INTEGER"},
+		{`"a" < 1`, "TypeError: type mismatch: STRING < INTEGER"},
+		{`"ab" * -1`, "ValueError: negative repeat count: -1"},
+		{`"ab" * 9223372036854775807`, "ValueError: repeated string too long: 2 bytes 9223372036854775807 times"},
+		{`"ab" * "cd"`, "TypeError: unknown operator: STRING * STRING"},
+		{`1 in "abc"`, "TypeError: type mismatch: INTEGER in STRING"},
+		{`1 in 2`, "TypeError: unknown operator: INTEGER in INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testErrorObject(t, evaluated, tt.expectedError)
+	}
+}
+
+func TestOperatorRegistry(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"if (false) { 1 } == if (false) { 1 }", true},
+		{"if (false) { 1 } == 5", false},
+		{"5 != if (false) { 1 }", true},
+		{`!"abc"`, true},
+		{`!""`, true},
+		{"!fn() {}", true},
+		{"!(9223372036854775807 + 1)", false},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+
+	// A new operator, registered without touching the evaluator.
+	object.RegisterInfix("-", object.STRING_OBJ, object.STRING_OBJ, func(a, b object.Object) object.Object {
+		return &object.String{Value: strings.ReplaceAll(a.(*object.String).Value, b.(*object.String).Value, "")}
+	})
+	defer delete(object.INFIX_OPERATORS, object.InfixOperands{Operator: "-", Left: object.STRING_OBJ, Right: object.STRING_OBJ})
+
+	evaluated := testEval(`"banana" - "a"`)
+	str, ok := evaluated.(*object.String)
 	if !ok {
-		t.Errorf("object is not of type Boolean. Got=%T (%+v)", obj, obj)
-		return false
+		t.Fatalf("object is not String. Got=%T (%+v)", evaluated, evaluated)
 	}
+	if str.Value != "bnn" {
+		t.Errorf("String has wrong value. Expected=%q, got=%q", "bnn", str.Value)
+	}
+}
 
-	if result.Value != expected {
-		t.Errorf("object has wrong value. Expect=%t, got=%t", expected, result.Value)
-		return false
+// User defined type, standing for records and classes, whose special methods see its amount.
+type testMoney struct {
+	amount  int64
+	methods map[string]object.Object
+}
+
+func newTestMoney(amount int64, methods map[string]string) *testMoney {
+	env := object.NewEnvironment()
+	env.Set("amount", object.NewInteger(amount))
+
+	m := &testMoney{amount: amount, methods: map[string]object.Object{}}
+	for name, source := range methods {
+		m.methods[name] = Eval(parser.New(lexer.New(source)).ParseProgram(), env)
 	}
 
-	return true
+	return m
+}
+
+func (m *testMoney) Type() object.ObjectType { return "MONEY" }
+func (m *testMoney) Inspect() string         { return "money" }
+func (m *testMoney) Truthy() bool            { return true }
 
+func (m *testMoney) GetMethod(name string) (object.Object, bool) {
+	method, ok := m.methods[name]
+	return method, ok
 }
 
-func testNullObject(t *testing.T, obj object.Object) {
-	if obj.Type() == object.NULL_OBJ {
-		return
+func TestOperatorOverloading(t *testing.T) {
+	money := newTestMoney(10, map[string]string{
+		"__add__":      "fn(other) { amount + other }",
+		"__eq__":       "fn(other) { amount == other }",
+		"__lt__":       "fn(other) { amount < other }",
+		"__contains__": "fn(other) { other < amount }",
+	})
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"m + 5", 15},
+		{"m == 10", true},
+		{"m == 11", false},
+		{"m != 11", true},
+		{"m < 11", true},
+		{"3 in m", true},
+		{"m - 5", "TypeError: type mismatch: MONEY - INTEGER"},
+		{"m == if (false) { 1 }", false},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		resolver.Resolve(program)
+
+		env := object.NewEnvironment()
+		env.Set("m", money)
+		evaluated := Eval(program, env)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestStructs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"struct Point { x, y }; let p = Point{x: 1, y: 2}; p.x + p.y", 3},
+		{"struct Point { x, y }; Point{y: 2, x: 1}.x", 1},
+		{"struct Point { x, y }; let p = Point{x: 1}; p.y", nil},
+		{"struct Point { x, y }; let p = Point{x: 1, y: 2}; p.x = 10; p.x", 10},
+		{"struct Point { x, y }; let p = Point{x: 1, y: 2}; p.x = p.y = 5; p.x + p.y", 10},
+		{"struct Box { v }; let b = Box{v: Box{v: 7}}; b.v.v", 7},
+		{"struct Counter { n }; let inc = fn(c) { c.n = c.n + 1 }; let c = Counter{n: 0}; inc(c); inc(c); c.n", 2},
+		{"let f = fn() { struct Point { x }; Point{x: 3} }; f().x", 3},
+		{"struct Point { x, y }; Point{x: 1, y: 2}", "Point{x: 1, y: 2}"},
+		{"struct Point { x, y }; Point", "struct Point { x, y }"},
+		{"struct N { next }; let n = N{}; n.next = n; n", "N{next: N{...}}"},
+		{"struct N { next }; let n = N{}; n.next = N{next: n}; n", "N{next: N{next: N{...}}}"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case nil:
+			testNullObject(t, evaluated)
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("wrong inspect output. Expected=%q, got=%q", expected, evaluated.Inspect())
+			}
+		}
+	}
+}
+
+func TestStructErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"struct Point { x }; Point{z: 1}", "AttributeError: unknown field z of struct Point"},
+		{"struct Point { x }; Point{x: 1}.z", "AttributeError: unknown member z of STRUCT_INSTANCE"},
+		{"struct Point { x }; let p = Point{x: 1}; p.z = 1", "AttributeError: cannot assign member z of STRUCT_INSTANCE"},
+		{"let p = 5; p{x: 1}", "TypeError: not a struct: INTEGER"},
+		{"let p = 5; p.x", "AttributeError: unknown member x of INTEGER"},
+		{"let p = 5; p.x = 1", "AttributeError: cannot assign member x of INTEGER"},
+		{"struct Point { x }; Point{x: foo}", "NameError: identifier not found: foo"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testErrorObject(t, evaluated, tt.expectedError)
+	}
+}
+
+const testClasses = `
+class Point {
+	init(x, y) { self.x = x; self.y = y; }
+	sum() { self.x + self.y }
+	scale(k) { Point(self.x * k, self.y * k) }
+	__add__(other) { Point(self.x + other.x, self.y + other.y) }
+	__eq__(other) { if (self.x == other.x) { self.y == other.y } else { false } }
+}
+
+class Solid extends Point {
+	init(x, y, z) { super.init(x, y); self.z = z; }
+	sum() { super.sum() + self.z }
+}
+
+class Tesseract extends Solid {
+	init(x, y, z, w) { super.init(x, y, z); self.w = w; }
+	sum() { super.sum() + self.w }
+}
+`
+
+func TestClasses(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"Point(1, 2).sum()", 3},
+		{"let p = Point(1, 2); p.x = 10; p.sum()", 12},
+		{"Point(1, 2).scale(3).y", 6},
+		{"(Point(1, 2) + Point(3, 4)).sum()", 10},
+		{"Point(1, 2) == Point(1, 2)", true},
+		{"Point(1, 2) != Point(1, 3)", true},
+		{"Solid(1, 2, 3).sum()", 6},
+		{"Tesseract(1, 2, 3, 4).sum()", 10},
+		{"Tesseract(1, 2, 3, 4).scale(2).sum()", 6},
+		{"let s = Point(1, 2).sum; s()", 3},
+		{"class Empty {}; Empty()", "Empty{}"},
+		{"Point(1, 2)", "Point{x: 1, y: 2}"},
+		{"Solid", "class Solid"},
+		{"let f = fn(n) { class Adder { add(x) { x + n } }; Adder() }; f(5).add(2)", 7},
+		{"class Counter { init() { self.n = 0; } inc() { self.n = self.n + 1; self } }; Counter().inc().inc().n", 2},
+		{"class A { who() { 1 } call() { self.who() } }; class B extends A { who() { 2 } }; B().call()", 2},
+		{"class Loop { init() { self.me = self; } }; Loop()", "Loop{me: Loop{...}}"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(testClasses + tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("wrong inspect output. Expected=%q, got=%q", expected, evaluated.Inspect())
+			}
+		}
+	}
+}
+
+func TestClassErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"Point(1)", "ArgumentError: wrong number of arguments: want=2, got=1"},
+		{"class Empty {}; Empty(1)", "ArgumentError: wrong number of arguments: want=0, got=1"},
+		{"Point(1, 2).missing()", "AttributeError: unknown member missing of INSTANCE"},
+		{"Point(1, 2) - Point(1, 2)", "TypeError: unknown operator: INSTANCE - INSTANCE"},
+		{"let x = 5; class A extends x {}", "TypeError: superclass must be a class: INTEGER"},
+		{"class A { init() { foo } }; A()", "NameError: identifier not found: foo"},
+		{"class A { f() { super.f() } }; A().f()", "AttributeError: unknown member f of NULL"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(testClasses + tt.input)
+
+		testErrorObject(t, evaluated, tt.expectedError)
+	}
+}
+
+func TestExceptions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`try { throw "boom"; 1 } catch (e) { e.message }`, "boom"},
+		{`try { throw 5 } catch (e) { e.value + 1 }`, 6},
+		{`try { throw 5 } catch (e) { e.type }`, "Error"},
+		{`try { 1 / 0 } catch (e) { e.type }`, "ZeroDivisionError"},
+		{`try { 1 / 0 } catch (e) { e.message }`, "division by 0"},
+		{`try { 1 + true } catch (e) { e.type }`, "TypeError"},
+		{`try { foo } catch (e) { e.type + ": " + e.message }`, "NameError: identifier not found: foo"},
+		{`try { 5() } catch (e) { e.type }`, "TypeError"},
+		{`try { fn(x) { x }() } catch (e) { e.type }`, "ArgumentError"},
+		{`try { 1 / 0 } catch (e) { e.value }`, nil},
+		{`try { 10 } catch (e) { 20 }`, 10},
+		{`let f = fn() { throw "inner" }; let g = fn() { f() + 1 }; try { g() } catch (e) { e.message }`, "inner"},
+		{`try { try { throw 1 } catch (e) { throw e } } catch (e) { e.value }`, 1},
+		{`try { try { throw 1 } finally { 2 } } catch (e) { e.value }`, 1},
+		{`class NotFound { init(m) { self.message = m } }; try { throw NotFound("no such key") } catch (e) { e.type + ": " + e.message }`, "NotFound: no such key"},
+		{`class NotFound { init(m) { self.message = m } }; try { throw NotFound("k") } catch (e) { e.value.message }`, "k"},
+		{`try { throw "x" } catch (e) { e }`, "Error: x"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case nil:
+			testNullObject(t, evaluated)
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("input %q: wrong value. Expected=%q, got=%q", tt.input, expected, evaluated.Inspect())
+			}
+		}
+	}
+}
+
+func TestFinally(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		// Runs after a return, without changing the returned value.
+		{"let c = Cell(0); let f = fn() { try { return 1; } finally { c.v = 2; } }; f() + c.v * 10", 21},
+		// Runs after an error was caught.
+		{"let c = Cell(0); try { 1 / 0 } catch (e) { c.v = 1 } finally { c.v = c.v + 1 }; c.v", 2},
+		// Runs when the error is not caught, which keeps unwinding.
+		{"let c = Cell(0); let f = fn() { try { 1 / 0 } finally { c.v = 3 } }; try { f() } catch (e) { c.v }", 3},
+		// A return from finally takes precedence.
+		{"let f = fn() { try { return 1; } finally { return 2; } }; f()", 2},
+		{"let f = fn() { try { throw 1 } finally { return 2; } }; f()", 2},
+		// Calls returned from a try block are not tail calls, so their errors are caught.
+		{"let bad = fn() { 1 / 0 }; let f = fn() { try { return bad(); } catch (e) { 7 } }; f()", 7},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval("class Cell { init(v) { self.v = v } }; "+tt.input), tt.expected)
+	}
+}
+
+func TestUncaughtErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedKind    string
+	}{
+		{`throw "boom"`, "boom", "Error"},
+		{`let f = fn() { throw 1 }; f(); 2`, "1", "Error"},
+		{`try { 1 / 0 } catch (e) { foo }`, "identifier not found: foo", "NameError"},
+		{`try { 1 } finally { throw 2 }`, "2", "Error"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testErrorObject(t, evaluated, tt.expectedKind+": "+tt.expectedMessage)
+	}
+}
+
+func TestFunctionDeclarations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"fn add(a, b) { a + b }; add(1, 2)", 3},
+		{"let x = double(4); fn double(n) { n * 2 } x", 8},
+		{"fn isEven(n) { if (n == 0) { true } else { isOdd(n - 1) } } fn isOdd(n) { if (n == 0) { false } else { isEven(n - 1) } } if (isOdd(7)) { 1 } else { 0 }", 1},
+		{"let f = fn(n) { let r = g(n); fn g(x) { x + h() } fn h() { 10 } r }; f(5)", 15},
+		{"fn f() { 1 }; let g = f; fn f() { 2 }; g()", 2},
+		{"let f = fn() { if (true) { fn g() { 7 } g() } }; f()", 7},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	inspects := []struct {
+		input    string
+		expected string
+	}{
+		{"fn add(a, b) { a + b }; add", "fn add(a, b) {\n(a + b)\n}"},
+		{"let sq = fn(x) { x * x }; sq", "fn sq(x) {\n(x * x)\n}"},
+		{"let sq = fn(x) { x * x }; let f = sq; f", "fn sq(x) {\n(x * x)\n}"},
+		{"fn(x) { x }", "fn(x) {\nx\n}"},
+		{"class A { get() { 1 } }; A().get", "fn A.get() {\n1\n}"},
+	}
+
+	for _, tt := range inspects {
+		if got := testEval(tt.input).Inspect(); got != tt.expected {
+			t.Errorf("wrong inspect for %q. Expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestArraysAndHashes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2 * 2, \"a\" + \"b\"]", "[1, 4, ab]"},
+		{"[]", "[]"},
+		{"let two = \"two\"; {\"one\": 10 - 9, two: 1 + 1, 3: 3, true: [4]}", "{one: 1, two: 2, 3: 3, true: [4]}"},
+		{"{\"a\": 1, \"b\": 2, \"a\": 3}", "{a: 3, b: 2}"},
+		{"[1, 2, 3][0]", "1"},
+		{"let i = 0; [1][i]", "1"},
+		{"let xs = [1, 2, 3]; xs[1] + xs[2]", "5"},
+		{"[1, 2, 3][3]", "null"},
+		{"[1, 2, 3][-1]", "null"},
+		{"{\"foo\": 5}[\"foo\"]", "5"},
+		{"{\"foo\": 5}[\"bar\"]", "null"},
+		{"{5: 5}[5]", "5"},
+		{"{true: 5}[true]", "5"},
+		{"let h = {\"f\": fn(x) { [x, x] }}; h[\"f\"](2)[1]", "2"},
+	}
+
+	for _, tt := range tests {
+		if got := testEval(tt.input).Inspect(); got != tt.expected {
+			t.Errorf("wrong value for %q. Expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestCollectionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{fn(x) { x }: 1}", "TypeError: unusable as hash key: FUNCTION"},
+		{"{\"a\": 1}[[1]]", "TypeError: unusable as hash key: ARRAY"},
+		{"[1][\"a\"]", "TypeError: index operator not supported: ARRAY[STRING]"},
+		{"1[0]", "TypeError: index operator not supported: INTEGER[INTEGER]"},
+		{"[1, foo]", "NameError: identifier not found: foo"},
+	}
+
+	for _, tt := range tests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestMatchExpressions(t *testing.T) {
+	describe := `
+	let describe = fn(x) {
+		match (x) {
+			0 => "zero",
+			-1 => "minus one",
+			true => "yes",
+			"hi" => "greeting",
+			[] => "empty",
+			[a] => "one: " + a,
+			[a, [b, _]] if a == b => "nested pair",
+			[_, [_, _]] => "nested",
+			[a, b] if a < b => "ascending",
+			[_, _] => "pair",
+			{"name": name, "age": 30} => name + " is thirty",
+			{"name": name} => name,
+			_ => "other",
+		}
+	};
+	`
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"describe(0)", "zero"},
+		{"describe(-1)", "minus one"},
+		{"describe(true)", "yes"},
+		{"describe(false)", "other"},
+		{"describe(\"hi\")", "greeting"},
+		{"describe([])", "empty"},
+		{"describe([\"x\"])", "one: x"},
+		{"describe([1, [1, 2]])", "nested pair"},
+		{"describe([1, [2, 2]])", "nested"},
+		{"describe([1, 2])", "ascending"},
+		{"describe([2, 1])", "pair"},
+		{"describe([1, 2, 3])", "other"},
+		{"describe({\"name\": \"ann\", \"age\": 30})", "ann is thirty"},
+		{"describe({\"name\": \"bob\", \"age\": 31})", "bob"},
+		{"describe({\"age\": 30})", "other"},
+		{"describe(7)", "other"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(describe + tt.input)
+
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Errorf("object is not of type String for %q. Got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if str.Value != tt.expected {
+			t.Errorf("wrong arm for %q. Expected=%q, got=%q", tt.input, tt.expected, str.Value)
+		}
+	}
+}
+
+func TestMatchScoping(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		// Pattern bindings hide outer bindings of the same name, and do not outlive their arm.
+		{"let a = 1; let b = match ([5]) { [a] => a }; a + b", 6},
+		{"let f = fn(a) { let r = match (2) { a if a > 1 => a * 10 }; r + a }; f(1)", 21},
+		{"let f = fn(k) { match (k) { x => fn(y) { x + y + k } } }; f(1)(2)", 4},
+		{"let f = fn(n, acc) { match (n) { 0 => acc, _ => f(n - 1, acc + n) } }; f(10000, 0)", 50005000},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"match (3) { 1 => 1, n if n < 0 => 2 }", "ValueError: no match arm for 3"},
+		{"match ([1]) { [n] if n < \"a\" => n, _ => 0 }", "TypeError: type mismatch: INTEGER < STRING"},
+		{"match (foo) { _ => 0 }", "NameError: identifier not found: foo"},
+	}
+
+	for _, tt := range errors {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestLetPatterns(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = [1, 2]; a + b", "3"},
+		{"let [a, _, c] = [1, 2, 3]; [a, c]", "[1, 3]"},
+		{"let [first, ...rest] = [1, 2, 3]; rest", "[2, 3]"},
+		{"let [x, y, ...rest] = [1, 2]; rest", "[]"},
+		{"let [[a, b], c] = [[1, 2], 3]; a * b * c", "6"},
+		{"let {name, age} = {\"name\": \"ann\", \"age\": 30, \"city\": \"x\"}; [name, age]", "[ann, 30]"},
+		{"let {\"pos\": [x, y], 1: one} = {1: \"a\", \"pos\": [3, 4]}; [x, y, one]", "[3, 4, a]"},
+		{"struct P { x, y }; let {x, y} = P{x: 1, y: 2}; x - y", "-1"},
+		{"class A { init() { self.v = 5 } }; let {v} = A(); v", "5"},
+		{"let [a, 2] = [1, 2]; a", "1"},
+		{"let f = fn(pair) { let [a, b] = pair; let [c] = [a * b]; c }; f([3, 4])", "12"},
+		{"let f = fn(xs) { let [head, ...tail] = xs; if (tail) { head + f(tail) } else { head } }; f([1, 2, 3, 4])", "10"},
+		{"let xs = [1, 2, 3]; let [a, ...rest] = xs; xs", "[1, 2, 3]"},
+		{"match ([1, 2, 3]) { [] => 0, [x, ...xs] => xs }", "[2, 3]"},
+		{"match ({\"kind\": \"circle\", \"r\": 2}) { {\"kind\": \"square\", side} => side, {\"kind\": \"circle\", r} => r * 3 }", "6"},
+	}
+
+	for _, tt := range tests {
+		if got := testEval(tt.input).Inspect(); got != tt.expected {
+			t.Errorf("wrong value for %q. Expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestLetPatternErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = 1", "TypeError: cannot destructure INTEGER as an array"},
+		{"let [a, b] = [1]", "ValueError: wrong number of elements: want=2, got=1"},
+		{"let [a, b] = [1, 2, 3]", "ValueError: wrong number of elements: want=2, got=3"},
+		{"let [a, b, ...rest] = [1]", "ValueError: too few elements: want at least 2, got=1"},
+		{"let [a, [b, c]] = [1, 2]", "TypeError: cannot destructure INTEGER as an array"},
+		{"let {name} = [1]", "TypeError: cannot destructure ARRAY as a hash"},
+		{"let {name, age} = {\"name\": 1}", "KeyError: missing key age"},
+		{"struct P { x }; let {y} = P{x: 1}", "KeyError: missing key y"},
+		{"let [a, 2] = [1, 3]", "ValueError: 3 does not match pattern 2"},
+		{"let [a] = [foo]", "NameError: identifier not found: foo"},
+	}
+
+	for _, tt := range tests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestBlockScoping(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		// A block's bindings do not leak, and hide the outer ones of the same names.
+		{"if (true) { let a = 1; }; a", "NameError: identifier not found: a"},
+		{"let a = 1; if (true) { let a = 2; a }", 2},
+		{"let a = 1; if (true) { let a = 2; }; a", 1},
+		{"let f = fn() { let a = 1; if (true) { let a = 2; }; a }; f()", 1},
+		{"let a = 1; if (false) { 0 } else { let a = 3; }; a", 1},
+		{"if (true) { fn g() { 5 } }; g()", "NameError: identifier not found: g"},
+		{"match (1) { x => x }; x", "NameError: identifier not found: x"},
+		{"try { let a = 1; throw 2 } catch (e) { let b = e; }; e", "NameError: identifier not found: e"},
+		{"try { let a = 1; } finally { }; a", "NameError: identifier not found: a"},
+		// Assignment sets the binding where it is, however deep.
+		{"let a = 1; if (true) { a = 2; }; a", 2},
+		{"let a = 1; if (true) { let a = 5; a = 2; }; a", 1},
+		{"let a = 1; if (true) { if (true) { a = a + 10 } }; a", 11},
+		{"let counter = fn() { let n = 0; fn() { n = n + 1 } }; let c = counter(); c(); c(); c()", 3},
+		{"let f = fn() { x = 1 }; f()", "NameError: identifier not found: x"},
+		{"let a = 1; let b = a = 2; a + b", 4},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestConstBindings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"const a = 5; a * 2", 10},
+		{"const a = 5; a = 6", "TypeError: cannot assign to constant a"},
+		{"const a = 5; if (true) { a = 6 }", "TypeError: cannot assign to constant a"},
+		{"let f = fn() { const a = 5; fn() { a = 1 } }; f()()", "TypeError: cannot assign to constant a"},
+		{"const a = 5; let a = 6", "TypeError: cannot redeclare constant a"},
+		{"const a = 5; const a = 6", "TypeError: cannot redeclare constant a"},
+		{"let f = fn() { const a = 5; let a = 6; }; f()", "TypeError: cannot redeclare constant a"},
+		{"const a = 5; struct a { x }", "TypeError: cannot redeclare constant a"},
+		{"const [a, ...b] = [1, 2]; b = 3", "TypeError: cannot assign to constant b"},
+		// A constant can be hidden by a binding of an inner scope, which can itself be assigned.
+		{"const a = 5; if (true) { let a = 6; a = 7; a }", 7},
+		{"const a = 5; let f = fn(a) { a = a + 1 }; f(1)", 2},
+		{"let a = 5; const b = a; a = 6; b", 5},
+		{"const a = 5; if (true) { const a = 6; a } + a", 11},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestStackTraces(t *testing.T) {
+	tests := []struct {
+		input             string
+		expectedTraceback string
+	}{
+		{"1 / 0", "ZeroDivisionError: division by 0"},
+		{
+			"let div = fn(a, b) { a / b };\nlet half = fn(a) {\n  let h = div(a, 0);\n  h\n};\nhalf(4)",
+			"Traceback (most recent call last):\n  in half, called at line 6, column 5\n  in div, called at line 3, column 14\nZeroDivisionError: division by 0",
+		},
+		{
+			"let f = fn() { fn() { foo }() + 1 };\nf()",
+			"Traceback (most recent call last):\n  in f, called at line 2, column 2\n  in <anonymous>, called at line 1, column 28\nNameError: identifier not found: foo",
+		},
+		// The tail call replaces the call of loop that made it.
+		{
+			"let loop = fn(n) { if (n == 0) { throw \"done\" }; loop(n - 1) };\nloop(3)",
+			"Traceback (most recent call last):\n  in loop, called at line 1, column 54\nError: done",
+		},
+		{
+			"class A { init(x) { self.x = x / 0 } };\nA(1)",
+			"Traceback (most recent call last):\n  in A.init, called at line 2, column 2\nZeroDivisionError: division by 0",
+		},
+		{
+			"class M { __add__(other) { other.x } };\nlet f = fn() { M() + 1 };\nf()",
+			"Traceback (most recent call last):\n  in f, called at line 3, column 2\n  in M.__add__, called at line 2, column 20\nAttributeError: unknown member x of INTEGER",
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. Got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if traceback := errObj.Traceback(); traceback != tt.expectedTraceback {
+			t.Errorf("wrong traceback. Expected=\n%s\ngot=\n%s", tt.expectedTraceback, traceback)
+		}
+	}
+}
+
+func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
+	result, ok := obj.(*object.Integer)
+	if !ok {
+		t.Errorf("object is not of type Integer. Got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value. Expect=%d, got=%d", expected, result.Value)
+		return false
+	}
+
+	return true
+
+}
+
+// Checks that the object is an error, printed as the kind and the message as in "TypeError: not a function".
+func testErrorObject(t *testing.T, obj object.Object, expected string) bool {
+	errObj, ok := obj.(*object.Error)
+	if !ok {
+		t.Errorf("no error object returned. Got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if got := errObj.KindName() + ": " + errObj.Message; got != expected {
+		t.Errorf("wrong error. Expected=%q, got=%q", expected, got)
+		return false
+	}
+
+	return true
+}
+
+// Checks that the object prints as expected, as an error if it is one.
+func testInspect(t *testing.T, obj object.Object, expected string) bool {
+	if _, ok := obj.(*object.Error); ok {
+		return testErrorObject(t, obj, expected)
+	}
+
+	if obj.Inspect() != expected {
+		t.Errorf("wrong value. Expected=%q, got=%q", expected, obj.Inspect())
+		return false
+	}
+
+	return true
+}
+
+func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
+	result, ok := obj.(*object.Boolean)
+	if !ok {
+		t.Errorf("object is not of type Boolean. Got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value. Expect=%t, got=%t", expected, result.Value)
+		return false
+	}
+
+	return true
+
+}
+
+func testNullObject(t *testing.T, obj object.Object) {
+	if obj.Type() == object.NULL_OBJ {
+		return
+	}
+	t.Errorf("object of wrong value. Expected=%s, got=%T", obj.Type(), obj)
+}
+
+const benchmarkFib = `
+let fib = fn(n) {
+	if (n < 2) { n } else { fib(n - 1) + fib(n - 2) }
+};
+fib(20);
+`
+
+const benchmarkClosures = `
+let compose = fn(f, g) { fn(x) { g(f(x)) } };
+let adder = fn(n) { fn(x) { x + n } };
+let loop = fn(i, acc) {
+	if (i == 0) {
+		acc
+	} else {
+		let step = compose(adder(i), adder(1));
+		loop(i - 1, step(acc))
+	}
+};
+loop(500, 0);
+`
+
+// Compares looking identifiers up by name through the environments with the slots computed by the resolver.
+func benchmarkEnvironments(b *testing.B, input string) {
+	b.Run("map", func(b *testing.B) {
+		program := parser.New(lexer.New(input)).ParseProgram()
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			Eval(program, object.NewEnvironment())
+		}
+	})
+
+	b.Run("slots", func(b *testing.B) {
+		program := parser.New(lexer.New(input)).ParseProgram()
+		resolver.Resolve(program)
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			Eval(program, object.NewEnvironment())
+		}
+	})
+}
+
+func BenchmarkFib(b *testing.B) {
+	benchmarkEnvironments(b, benchmarkFib)
+}
+
+func BenchmarkClosures(b *testing.B) {
+	benchmarkEnvironments(b, benchmarkClosures)
+}
+
+func benchmarkAllocations(b *testing.B, input string) {
+	program := parser.New(lexer.New(input)).ParseProgram()
+	resolver.Resolve(program)
+	env := object.NewEnvironment()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		Eval(program, env)
+	}
+}
+
+func BenchmarkComparisonAllocations(b *testing.B) {
+	benchmarkAllocations(b, "(1 < 2) == (3 > 4) != (5 == 5) == !(6 != 7)")
+}
+
+func BenchmarkSmallIntegerAllocations(b *testing.B) {
+	benchmarkAllocations(b, "(1 + 2) * 3 - 4 / 2 + -5")
+}
+
+// Writes the module files, by path relative to a temporary directory, and returns the directory.
+func writeModules(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, source := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// Evaluates the program as the main module of the file at the path.
+func testEvalModule(loader *Loader, path, input string) object.Object {
+	program := parser.New(lexer.New(input)).ParseProgram()
+	resolver.Resolve(program)
+
+	return Eval(program, loader.Main(path).Env)
+}
+
+func TestModules(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"lib/math.mky": `
+			import "./counter.mky" as counter
+			export fn double(x) { counter.bump(); x * 2 }
+			export const TEN = 10;
+			let hidden = 1;
+		`,
+		"lib/counter.mky": `
+			export let count = 0;
+			export fn bump() { count = count + 1 }
+		`,
+		"vendor/greet.mky": `export let greeting = "hi";`,
+	})
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "lib/math.mky" as m; m.double(m.TEN)`, 20},
+		{`import "lib/math" as m; m.TEN`, 10},
+		{`import "./lib/math.mky" as m; m.hidden`, "unknown member hidden of MODULE"},
+		// Modules are evaluated once, and their exports reflect later assignments.
+		{`import "lib/math.mky" as m; import "lib/counter.mky" as c; m.double(1); m.double(2); c.count`, 2},
+		// Found in the search path.
+		{`import "greet"; greet.greeting`, "hi"},
+		{`let f = fn() { import "greet" as g; g.greeting }; f()`, "hi"},
+		{`import "lib/math.mky" as m; m.TEN = 1`, "cannot assign member TEN of MODULE"},
+	}
+
+	for _, tt := range tests {
+		loader := NewLoader(filepath.Join(dir, "vendor"))
+		evaluated := testEvalModule(loader, filepath.Join(dir, "main.mky"), tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			switch evaluated := evaluated.(type) {
+			case *object.String:
+				if evaluated.Value != expected {
+					t.Errorf("input %q: wrong value. Expected=%q, got=%q", tt.input, expected, evaluated.Value)
+				}
+			case *object.Error:
+				if evaluated.Message != expected {
+					t.Errorf("input %q: wrong error message. Expected=%q, got=%q", tt.input, expected, evaluated.Message)
+				}
+			default:
+				t.Errorf("input %q: unexpected result %T(%+v)", tt.input, evaluated, evaluated)
+			}
+		}
+	}
+}
+
+func TestModuleErrors(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"a.mky":      `import "b.mky" as b; export let x = 1;`,
+		"b.mky":      `import "a.mky" as a; export let y = 2;`,
+		"self.mky":   `import "./main.mky" as m;`,
+		"broken.mky": `let = 5;`,
+		"failing.mky": `export let x = 1;
+fn f() { 1 / 0 }
+f()`,
+		"nested.mky": `if (true) { export let x = 1; }`,
+		"ok.mky":     `export let v = 1;`,
+		"main.mky":   ``,
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "missing" as m`, "ImportError: module not found: missing"},
+		{`import "a" as a`, "Traceback (most recent call last):\n  in <module a>, called at line 1, column 1\n  in <module b>, called at line 1, column 1\nImportError: import cycle: a.mky -> b.mky -> a.mky"},
+		{`import "self" as s`, "Traceback (most recent call last):\n  in <module self>, called at line 1, column 1\nImportError: import cycle: main.mky -> self.mky -> main.mky"},
+		{`import "broken" as b`, "ImportError: cannot parse module broken: expected next token to be IDENT, got = instead; no prefix parse function for = found"},
+		{`import "failing" as f`, "Traceback (most recent call last):\n  in <module failing>, called at line 1, column 1\n  in f, called at line 3, column 2\nZeroDivisionError: division by 0"},
+		{`import "nested" as n`, "Traceback (most recent call last):\n  in <module nested>, called at line 1, column 1\nImportError: export is only allowed at the top level of a module"},
+		{`const ok = 1; import "ok"`, "TypeError: cannot redeclare constant ok"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalModule(NewLoader(), filepath.Join(dir, "main.mky"), tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("input %q: no error object returned. Got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Traceback() != tt.expected {
+			t.Errorf("input %q: wrong traceback. Expected=%q, got=%q", tt.input, tt.expected, errObj.Traceback())
+		}
+	}
+
+	// Outside of a module, there is nothing to import from, nor to export to.
+	for _, input := range []string{`import "a" as a`, "export let x = 1"} {
+		errObj, ok := testEval(input).(*object.Error)
+		if !ok || errObj.Kind != object.IMPORT_ERROR {
+			t.Errorf("input %q: expected an import error. Got=%+v", input, errObj)
+		}
+	}
+}
+
+func TestStringsModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`strings.split("a,b,,c", ",")`, `[a, b, , c]`},
+		{`strings.split("  a  b c ")`, `[a, b, c]`},
+		{`strings.join(["a", "b", "c"], "-")`, "a-b-c"},
+		{`strings.join(["a", "b"])`, "ab"},
+		{`strings.join(strings.split("a b", " "), "+")`, "a+b"},
+		{"strings.trim(\"  hi \t\")", "hi"},
+		{`strings.trim("xxhixx", "x")`, "hi"},
+		{`strings.upper("Hello")`, "HELLO"},
+		{`strings.lower("Hello")`, "hello"},
+		{`strings.replace("a-b-c", "-", "+")`, "a+b+c"},
+		{`strings.replace("a-b-c", "-", "+", 1)`, "a+b-c"},
+		{`strings.contains("hello", "ell")`, true},
+		{`strings.contains("hello", "xyz")`, false},
+		{`strings.index("héllo", "l")`, 2},
+		{`strings.index("hello", "z")`, -1},
+		{`strings.startsWith("hello", "he")`, true},
+		{`strings.endsWith("hello", "he")`, false},
+		{`strings.repeat("ab", 3)`, "ababab"},
+		{`strings.substr("héllo", 1, 3)`, "éll"},
+		{`strings.substr("hello", -3)`, "llo"},
+		{`strings.substr("hello", 3, 10)`, "lo"},
+		{`strings.substr("hello", 10)`, ""},
+		{`strings.substr("abc", 1, 9223372036854775807)`, "bc"},
+		{`strings.format("{} + {} = {}", 1, 2, 1 + 2)`, "1 + 2 = 3"},
+		{`strings.format("{1}{0}{1}", "a", "b")`, "bab"},
+		{`strings.format("{{{}}}", [1, "x"])`, "{[1, x]}"},
+		{`import "strings" as s; s.upper("x")`, "X"},
+		{`strings.upper`, "builtin upper"},
+		{`let f = fn(x) { strings.upper(x) }; f("tail")`, "TAIL"},
+
+		// Errors
+		{`strings.upper(1)`, "TypeError: argument 1 to strings.upper must be STRING, got INTEGER"},
+		{`strings.upper()`, "ArgumentError: wrong number of arguments: want=1, got=0"},
+		{`strings.split("a", "b", "c")`, "ArgumentError: wrong number of arguments: want=1 to 2, got=3"},
+		{`strings.join(["a", 1])`, "TypeError: element 1 of argument 1 to strings.join must be STRING, got INTEGER"},
+		{`strings.repeat("a", -1)`, "ValueError: negative repeat count: -1"},
+		{`strings.repeat("ab", 9223372036854775807)`, "ValueError: repeated string too long: 2 bytes 9223372036854775807 times"},
+		{`strings.substr("a", 0, -1)`, "ValueError: negative length: -1"},
+		{`strings.format("{} {}", 1)`, "ValueError: missing argument 1 for format string"},
+		{`strings.format("{x}", 1)`, "ValueError: invalid format field {x}"},
+		{`strings.format("{", 1)`, "ValueError: unmatched { in format string"},
+		{`strings.format("}")`, "ValueError: unmatched } in format string"},
+		{`strings.format()`, "ArgumentError: wrong number of arguments: want=1, got=0"},
+		{`strings.nope`, "AttributeError: unknown member nope of MODULE"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(`import "strings"; ` + tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testInspect(t, evaluated, expected)
+		}
+	}
+}
+
+func TestBigIntegers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"4611686018427387904 * 2", "9223372036854775808"},
+		{"-(-9223372036854775807 - 1)", "9223372036854775808"},
+		{"(-9223372036854775807 - 1) / -1", "9223372036854775808"},
+		{"let fact = fn(n) { if (n < 2) { 1 } else { n * fact(n - 1) } }; fact(25)", "15511210043330985984000000"},
+		{"(9223372036854775807 + 1) - 1", "9223372036854775807"},
+		{"(9223372036854775807 + 1) / 2", "4611686018427387904"},
+		{"9223372036854775807 + 1 > 9223372036854775807", "true"},
+		{"9223372036854775807 + 1 == 9223372036854775807 + 1", "true"},
+		{"let h = {9223372036854775807 + 1: 1}; h[9223372036854775807 + 1]", "1"},
+		{"(9223372036854775807 + 1) / 0", "ZeroDivisionError: division by 0"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testInspect(t, evaluated, tt.expected)
+	}
+
+	if testEval("(9223372036854775807 + 1) - 1").Type() != object.INTEGER_OBJ {
+		t.Errorf("big integer results that fit in 64 bits should be integers")
+	}
+}
+
+func TestMathModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"math.abs(-5)", "5"},
+		{"math.abs(-9223372036854775807 - 1)", "9223372036854775808"},
+		{"math.min(3, 1, 2)", "1"},
+		{"math.max([3, 9223372036854775807 + 1, 2])", "9223372036854775808"},
+		{"math.pow(2, 10)", "1024"},
+		{"math.pow(2, 100)", "1267650600228229401496703205376"},
+		{"math.pow(5, 0)", "1"},
+		{"math.sqrt(17)", "4"},
+		{"math.sqrt(math.pow(10, 40))", "100000000000000000000"},
+		{"math.floor(7, 2)", "3"},
+		{"math.floor(-7, 2)", "-4"},
+		{"math.floor(7, -2)", "-4"},
+		{"math.gcd(12, -18)", "6"},
+		{"math.gcd(0, 0)", "0"},
+
+		// Errors
+		{"math.pow(2, -1)", "ValueError: negative exponent: -1"},
+		{"math.pow(2, 9223372036854775807)", "ValueError: power too large: 2 to the 9223372036854775807"},
+		{"math.pow(-1, 9223372036854775807)", "-1"},
+		{"math.sqrt(-4)", "ValueError: square root of negative number: -4"},
+		{"math.floor(1, 0)", "ZeroDivisionError: division by 0"},
+		{"math.floor(5)", "ArgumentError: wrong number of arguments: want=2, got=1"},
+		{"math.min([])", "ValueError: math.min of an empty array"},
+		{"math.min()", "ArgumentError: wrong number of arguments: want=1, got=0"},
+		{`math.max(1, "a")`, "TypeError: argument 2 to math.max must be INTEGER, got STRING"},
+		{`math.max([1, "a"])`, "TypeError: element 1 of argument 1 to math.max must be INTEGER, got STRING"},
+		{"math.gcd(1)", "ArgumentError: wrong number of arguments: want=2, got=1"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(`import "math"; ` + tt.input)
+
+		testInspect(t, evaluated, tt.expected)
+	}
+}
+
+func TestFunctionalBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"map([1, 2, 3], fn(x) { x * 2 })", "[2, 4, 6]"},
+		{"filter([1, 2, 3, 4], fn(x) { x > 2 })", "[3, 4]"},
+		{"reduce([1, 2, 3], fn(acc, x) { acc + x })", "6"},
+		{"reduce([], fn(acc, x) { acc + x }, 10)", "10"},
+		{`reduce(["a", "b"], fn(acc, x) { acc + x }, ">")`, ">ab"},
+		{"any([1, 2, 3], fn(x) { x > 2 })", "true"},
+		{"any([])", "false"},
+		{"all([1, 2, 3], fn(x) { x > 0 })", "true"},
+		{"all([1, 0, 3])", "false"},
+		{"all([])", "true"},
+		{"sort([3, 1, 2])", "[1, 2, 3]"},
+		{`sort(["b", "c", "a"])`, "[a, b, c]"},
+		{"sort([3, 1, 2], fn(a, b) { a > b })", "[3, 2, 1]"},
+		{"let a = [2, 1]; sort(a); a", "[2, 1]"},
+		{"zip([1, 2, 3], [4, 5])", "[[1, 4], [2, 5]]"},
+		{"range(4)", "[0, 1, 2, 3]"},
+		{"range(1, 4)", "[1, 2, 3]"},
+		{"range(5, 0, -2)", "[5, 3, 1]"},
+		{"range(0)", "[]"},
+		{"range(9223372036854775806, 9223372036854775807, 2)", "[9223372036854775806]"},
+		{"range(-9223372036854775807, -9223372036854775807 - 1, -2)", "[-9223372036854775807]"},
+		{"range(-9223372036854775807 - 1, 9223372036854775807, 9223372036854775807)", "[-9223372036854775808, -1, 9223372036854775806]"},
+		{`enumerate(["a", "b"])`, "[[0, a], [1, b]]"},
+		{"map(range(3), fn(x) { map(range(x), fn(y) { y }) })", "[[], [0], [0, 1]]"},
+		{"let double = fn(x) { x * 2 }; let f = fn() { map([1], double) }; f()", "[2]"},
+		{"let map = fn(a, f) { 0 }; map([1], fn(x) { x })", "0"},
+		{`import "strings"; map(["a"], strings.upper)`, "[A]"},
+
+		// Errors
+		{"map([1, 2], fn(x) { x + true })", "TypeError: type mismatch: INTEGER + BOOLEAN"},
+		{`map([1], fn(x) { throw "boom" })`, "Error: boom"},
+		{"filter([1], fn(a, b) { a })", "ArgumentError: wrong number of arguments: want=2, got=1"},
+		{"map([1], 1)", "TypeError: argument 2 to map must be FUNCTION, got INTEGER"},
+		{"map(1, fn(x) { x })", "TypeError: argument 1 to map must be ARRAY, got INTEGER"},
+		{"reduce([], fn(acc, x) { acc })", "ValueError: reduce of an empty array"},
+		{`sort([1, "a"])`, "TypeError: type mismatch: STRING < INTEGER"},
+		{"range(0, 1, 0)", "ValueError: range step cannot be zero"},
+		{"range(9223372036854775807)", "ValueError: range too large: 9223372036854775807 elements"},
+		{"zip([1], 2)", "TypeError: argument 2 to zip must be ARRAY, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testInspect(t, evaluated, tt.expected)
+	}
+}
+
+func TestJSONModule(t *testing.T) {
+	parseTests := []struct {
+		text     string
+		expected string
+	}{
+		{`{"b": [1, true, null], "a": "x"}`, "{b: [1, true, null], a: x}"},
+		{`"<&>"`, "<&>"},
+		{` 12345678901234567890 `, "12345678901234567890"},
+		{`[]`, "[]"},
+		{`{"a": 1, "a": 2}`, "{a: 2}"},
+		{`1.5`, "ValueError: json number 1.5 is not an integer"},
+		{`[1, 2`, "ValueError: invalid json: unexpected end of JSON input"},
+		{``, "ValueError: invalid json: unexpected end of input"},
+		{`1 2`, "ValueError: invalid json: unexpected data after the value"},
+		{`{1: 2}`, "ValueError: invalid json: object member name must be a string"},
+	}
+
+	for _, tt := range parseTests {
+		parsed := jsonParse(&object.String{Value: tt.text})
+
+		testInspect(t, parsed, tt.expected)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json.stringify({"b": [1, true, if (false) { 1 }], "a": "x"})`, `{"b":[1,true,null],"a":"x"}`},
+		{`json.stringify({"k": "<&>"})`, `{"k":"<&>"}`},
+		{`json.stringify([1, [2]], 2)`, "[\n  1,\n  [\n    2\n  ]\n]"},
+		{`json.stringify([], 2)`, "[]"},
+		{`json.stringify(9223372036854775807 + 1)`, "9223372036854775808"},
+		{`struct P { x, y }; json.stringify(P{y: 2, x: 1})`, `{"x":1,"y":2}`},
+		{`class C { init() { self.b = 1; self.a = 2; } } json.stringify(C())`, `{"a":2,"b":1}`},
+		{`let a = [1]; json.stringify([a, a])`, "[[1],[1]]"},
+		{`json.parse(json.stringify({"k": ["v", 1]}))`, "{k: [v, 1]}"},
+		{`json.parse(json.stringify("tab	"))`, "tab	"},
+
+		// Errors
+		{`json.stringify(fn(x) { x })`, "TypeError: cannot serialize FUNCTION to json"},
+		{`json.stringify([json.parse])`, "TypeError: cannot serialize BUILTIN to json"},
+		{`struct N { next }; let n = N{}; n.next = n; json.stringify(n)`, "ValueError: cannot serialize cyclic STRUCT_INSTANCE to json"},
+		{`json.stringify(1, -1)`, "ValueError: negative indent: -1"},
+		{`json.stringify([1], 9223372036854775807)`, "ValueError: indent too large: 9223372036854775807, at most 10"},
+		{`json.stringify({1: "a", "1": "b"})`, "TypeError: cannot serialize INTEGER key to json, keys must be strings"},
+		{`json.parse(1)`, "TypeError: argument 1 to json.parse must be STRING, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(`import "json"; ` + tt.input)
+
+		testInspect(t, evaluated, tt.expected)
+	}
+}
+
+func TestFSModule(t *testing.T) {
+	outside := writeModules(t, map[string]string{"secret.txt": "secret"})
+	root := writeModules(t, map[string]string{
+		"notes.txt":   "hello",
+		"data/a.txt":  "a",
+		"data/b.txt":  "b",
+		"data/in.mky": "",
+	})
+	if err := os.Symlink(outside, filepath.Join(root, "out")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "missing.txt"), filepath.Join(root, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`fs.read("notes.txt")`, "hello"},
+		{`fs.read("./data/../notes.txt")`, "hello"},
+		{`fs.write("new.txt", "one"); fs.append("new.txt", " two"); fs.read("new.txt")`, "one two"},
+		{`fs.write("new.txt", "three"); fs.read("new.txt")`, "three"},
+		{`fs.exists("new.txt")`, "true"},
+		{`fs.remove("new.txt"); fs.exists("new.txt")`, "false"},
+		{`fs.list("data")`, "[a.txt, b.txt, in.mky]"},
+		{`fs.list()`, "[dangling, data, notes.txt, out]"},
+		{`fs.exists("data")`, "true"},
+
+		// Errors
+		{`fs.read("../secret.txt")`, "IOError: path escapes the root directory: ../secret.txt"},
+		{`fs.read("` + filepath.Join(outside, "secret.txt") + `")`, "IOError: path escapes the root directory: " + filepath.Join(outside, "secret.txt")},
+		{`fs.read("out/secret.txt")`, "IOError: path escapes the root directory: out/secret.txt"},
+		{`fs.write("out/new.txt", "x")`, "IOError: path escapes the root directory: out/new.txt"},
+		{`fs.write("dangling", "x")`, "IOError: path escapes the root directory: dangling"},
+		{`fs.exists("..")`, "IOError: path escapes the root directory: .."},
+		{`fs.read("missing.txt")`, "IOError: missing.txt: no such file or directory"},
+		{`fs.list("notes.txt")`, "IOError: notes.txt: not a directory"},
+		{`fs.remove("")`, "IOError: cannot remove the root directory"},
+		{`fs.read(1)`, "TypeError: argument 1 to fs.read must be STRING, got INTEGER"},
+	}
+
+	loader := NewLoader()
+	loader.Provide(NewFSModule(root))
+
+	for _, tt := range tests {
+		evaluated := testEvalModule(loader, "", `import "fs"; `+tt.input)
+
+		testInspect(t, evaluated, tt.expected)
+	}
+
+	if _, err := os.Stat(filepath.Join(outside, "new.txt")); err == nil {
+		t.Errorf("fs.write wrote out of the root")
+	}
+
+	// Without a root, the host has not provided the module.
+	testErrorObject(t, testEvalModule(NewLoader(), "", `import "fs"`), "ImportError: module not found: fs")
+}
+
+func TestOutputBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		output   string
+		expected string
+	}{
+		{`print("a", 1); print([true])`, "a 1[true]", "null"},
+		{`println("a", 1); println()`, "a 1\n\n", "null"},
+		{`printf("%s=%d%%", "x", 10)`, "x=10%", "null"},
+		{`let f = fn(x) { println(x); x }; f(1) + f(2)`, "1\n2\n", "3"},
+		{`let print = fn(x) { x * 2 }; print(2)`, "", "4"},
+		{`sprintf("%v %q %d", [1, "a"], "b", 9223372036854775807 + 1)`, "", `[1, a] "b" 9223372036854775808`},
+
+		// Errors
+		{`printf("%d", "a")`, "", "TypeError: %d needs INTEGER, got STRING"},
+		{`sprintf("%s %s", 1)`, "", "ValueError: missing argument 1 for format string"},
+		{`sprintf("%s", 1, 2)`, "", "ValueError: 1 unused arguments for format string"},
+		{`sprintf("%x", 1)`, "", "ValueError: unknown verb %x in format string"},
+		{`sprintf("50%")`, "", "ValueError: unfinished verb at the end of format string"},
+		{`sprintf(1)`, "", "TypeError: argument 1 to sprintf must be STRING, got INTEGER"},
+	}
+
+	var out strings.Builder
+	defer func(previous io.Writer) { Output = previous }(Output)
+	Output = &out
+
+	for _, tt := range tests {
+		out.Reset()
+		evaluated := testEval(tt.input)
+
+		testInspect(t, evaluated, tt.expected)
+		if out.String() != tt.output {
+			t.Errorf("input %q: wrong output. Expected=%q, got=%q", tt.input, tt.output, out.String())
+		}
+	}
+
+	// Imported modules print to the same output.
+	out.Reset()
+	dir := writeModules(t, map[string]string{"hello.mky": `println("hello from", "module");`})
+	testEvalModule(NewLoader(), filepath.Join(dir, "main.mky"), `import "./hello.mky"`)
+	if out.String() != "hello from module\n" {
+		t.Errorf("wrong output of the imported module. Expected=%q, got=%q", "hello from module\n", out.String())
+	}
+}
+
+// Clock whose time only moves when the program sleeps.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestTimeModule(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`time.now()`, "1709296200000"},
+		{`let t = time.now(); time.sleep(1500); time.now() - t`, "1500"},
+		{`time.format(time.now())`, "2024-03-01T12:30:00Z"},
+		{`time.format(time.now() + time.HOUR, "2006-01-02 15:04")`, "2024-03-01 13:30"},
+		{`time.parse("2024-03-01T12:30:00Z") == time.now()`, "true"},
+		{`time.parse("01/02/1970", "01/02/2006")`, "86400000"},
+		{`time.duration("1h30m")`, "5400000"},
+		{`time.duration("1m") == time.MINUTE`, "true"},
+		{`time.SECOND`, "1000"},
+
+		// Errors
+		{`time.sleep(-1)`, "ValueError: negative duration: -1"},
+		{`time.sleep(9223372036854775807)`, "ValueError: duration too long: 9223372036854775807"},
+		{`time.parse("yesterday")`, `ValueError: cannot parse "yesterday" as a time of layout "2006-01-02T15:04:05Z07:00"`},
+		{`time.duration("soon")`, `ValueError: cannot parse "soon" as a duration`},
+		{`time.now(1)`, "ArgumentError: wrong number of arguments: want=0, got=1"},
+	}
+
+	for _, tt := range tests {
+		loader := NewLoader()
+		loader.Provide(NewTimeModule(&fakeClock{now: start}))
+
+		evaluated := testEvalModule(loader, "", `import "time"; `+tt.input)
+
+		testInspect(t, evaluated, tt.expected)
+	}
+}
+
+func TestRandomModule(t *testing.T) {
+	draws := `[random.int(100), random.int(-5, 5), random.choice(["a", "b", "c"]), random.shuffle(range(10))]`
+
+	run := func(seed int64, input string) object.Object {
+		loader := NewLoader()
+		loader.Provide(NewRandomModule(seed))
+		return testEvalModule(loader, "", `import "random"; `+input)
+	}
+
+	first, second := run(42, draws), run(42, draws)
+	if first.Inspect() != second.Inspect() {
+		t.Errorf("same seed gave different draws: %s and %s", first.Inspect(), second.Inspect())
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`all(map(range(50), fn(i) { let n = random.int(3, 6); if (n < 3) { false } else { n < 6 } }))`, "true"},
+		{`all(map(range(50), fn(i) { random.int(-9223372036854775807 - 1, 9223372036854775807) < 9223372036854775807 }))`, "true"},
+		{`random.int(9223372036854775806, 9223372036854775807)`, "9223372036854775806"},
+		{`random.choice([7])`, "7"},
+		{`sort(random.shuffle([3, 1, 2]))`, "[1, 2, 3]"},
+		{`let a = [1, 2]; random.shuffle(a); a`, "[1, 2]"},
+
+		// Errors
+		{`random.int(0)`, "ValueError: empty range from 0 to 0"},
+		{`random.int(5, 2)`, "ValueError: empty range from 5 to 2"},
+		{`random.choice([])`, "ValueError: random.choice of an empty array"},
+	}
+
+	for _, tt := range tests {
+		evaluated := run(1, tt.input)
+
+		testInspect(t, evaluated, tt.expected)
+	}
+}
+
+func TestRegexes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`/a+b/i`, "/a+b/i"},
+		{`re.compile("a+b", "i")`, "/a+b/i"},
+		{`re.match(/(\w+)=(\d+)/, "x: level=42")`, "[level=42, level, 42]"},
+		{`re.match(/(a)|(b)/, "b")`, "[b, null, b]"},
+		{`re.match(/z/, "abc")`, "null"},
+		{`re.match(/ABC/i, "xabc")`, "[abc]"},
+		{`re.match("b+", "abbc")`, "[bb]"},
+		{`re.findAll(/\d+/, "a1 b22 c333")`, "[1, 22, 333]"},
+		{`re.findAll(/\d/, "abc")`, "[]"},
+		{`re.replace(/(\w+)@(\w+)/, "me@host you@there", "$2:$1")`, "host:me there:you"},
+		{`re.split(/\s*,\s*/, "a , b,c")`, "[a, b, c]"},
+		{`let lines = ["ERROR disk", "INFO ok", "ERROR net"]; map(filter(lines, fn(l) { re.match(/^ERROR/, l) }), fn(l) { re.split(/ /, l)[1] })`, "[disk, net]"},
+		{`let r = /a/; 10 / 2 / r.x`, "AttributeError: unknown member x of REGEX"},
+		{`let half = fn(x) { x / 2 }; half(8) / 2`, "2"},
+
+		// Errors
+		{`/a(/`, "ValueError: invalid regex /a(/: missing closing )"},
+		{`/a/x`, "ValueError: invalid regex /a/x: unknown flag x"},
+		{`re.compile("[", "")`, "ValueError: invalid regex /[/: missing closing ]"},
+		{`re.match(1, "a")`, "TypeError: argument 1 to re.match must be REGEX, got INTEGER"},
+		{`re.split(/a/)`, "ArgumentError: wrong number of arguments: want=2, got=1"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(`import "re"; ` + tt.input)
+
+		testInspect(t, evaluated, tt.expected)
+	}
+}
+
+func TestRegexLiteralCompiledOnce(t *testing.T) {
+	program := parser.New(lexer.New(`/a+b/i`)).ParseProgram()
+	literal := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.RegexLiteral)
+
+	first, ok := Eval(program, object.NewEnvironment()).(*object.Regex)
+	if !ok || literal.Compiled == nil {
+		t.Fatalf("expected the literal to compile once evaluated")
+	}
+	second := Eval(program, object.NewEnvironment()).(*object.Regex)
+	if first.Value != second.Value {
+		t.Errorf("expected the second evaluation to reuse the compiled pattern")
+	}
+	if !second.Value.MatchString("xAAB") {
+		t.Errorf("expected the reused pattern to keep its flags")
 	}
-	t.Errorf("object of wrong value. Expected=%s, got=%T", obj.Type(), obj)
 }
@@ -0,0 +1,210 @@
+package eval
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MohamTahaB/interpreter-go/object"
+)
+
+// Confines the functions of the fs module to a directory, which the paths they are given are relative to.
+type sandbox struct {
+	root string
+}
+
+// Returns the fs module, reading and writing files under the root directory only.
+// Paths are resolved, symbolic links included, and rejected if they lead out of the root.
+func NewFSModule(root string) *object.Module {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+
+	sb := &sandbox{root: root}
+	return object.NewBuiltinModule("fs",
+		&object.Builtin{Name: "read", Fn: sb.read},
+		&object.Builtin{Name: "write", Fn: sb.write},
+		&object.Builtin{Name: "append", Fn: sb.append},
+		&object.Builtin{Name: "exists", Fn: sb.exists},
+		&object.Builtin{Name: "list", Fn: sb.list},
+		&object.Builtin{Name: "remove", Fn: sb.remove},
+	)
+}
+
+// read(path): the content of the file.
+func (sb *sandbox) read(args ...object.Object) object.Object {
+	if err := checkArgs("fs.read", args, 1, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	path, errObj := sb.resolve(args[0])
+	if errObj != nil {
+		return errObj
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fsError(args[0], err)
+	}
+
+	return &object.String{Value: string(content)}
+}
+
+// write(path, content): replaces the content of the file, created if it does not exist.
+func (sb *sandbox) write(args ...object.Object) object.Object {
+	return sb.writeFile("fs.write", args, os.O_TRUNC)
+}
+
+// append(path, content): adds the content at the end of the file, created if it does not exist.
+func (sb *sandbox) append(args ...object.Object) object.Object {
+	return sb.writeFile("fs.append", args, os.O_APPEND)
+}
+
+func (sb *sandbox) writeFile(name string, args []object.Object, mode int) object.Object {
+	if err := checkArgs(name, args, 2, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	path, errObj := sb.resolve(args[0])
+	if errObj != nil {
+		return errObj
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|mode, 0o644)
+	if err != nil {
+		return fsError(args[0], err)
+	}
+
+	_, err = file.WriteString(args[1].(*object.String).Value)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fsError(args[0], err)
+	}
+
+	return NULL
+}
+
+// exists(path): whether there is a file or a directory at the path.
+func (sb *sandbox) exists(args ...object.Object) object.Object {
+	if err := checkArgs("fs.exists", args, 1, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	path, errObj := sb.resolve(args[0])
+	if errObj != nil {
+		return errObj
+	}
+
+	_, err := os.Stat(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fsError(args[0], err)
+	}
+
+	return nativeBoolToBooleanObject(err == nil)
+}
+
+// list(path?): the sorted names of the entries of the directory, the root without a path.
+func (sb *sandbox) list(args ...object.Object) object.Object {
+	if err := checkArgs("fs.list", args, 0, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	dir := object.Object(&object.String{Value: "."})
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	path, errObj := sb.resolve(dir)
+	if errObj != nil {
+		return errObj
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fsError(dir, err)
+	}
+
+	names := make([]string, len(entries))
+	for idx, entry := range entries {
+		names[idx] = entry.Name()
+	}
+
+	return stringArray(names)
+}
+
+// remove(path): deletes the file, or the directory if it is empty. The root itself cannot be removed.
+func (sb *sandbox) remove(args ...object.Object) object.Object {
+	if err := checkArgs("fs.remove", args, 1, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	path, errObj := sb.resolve(args[0])
+	if errObj != nil {
+		return errObj
+	}
+	if path == sb.root {
+		return newError(ROOT_REMOVAL)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fsError(args[0], err)
+	}
+
+	return NULL
+}
+
+// Returns the absolute path the path stands for under the root, rejecting it if it leads out of the root.
+// Symbolic links are followed as far as the path exists, so that a link cannot point out of the root either.
+func (sb *sandbox) resolve(arg object.Object) (string, *object.Error) {
+	name := arg.(*object.String).Value
+
+	path := filepath.Join(sb.root, name)
+	if filepath.IsAbs(name) {
+		path = filepath.Clean(name)
+	}
+
+	// The part of the path that does not exist yet cannot hold links.
+	existing, rest := path, ""
+	for {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			path = filepath.Join(resolved, rest)
+			break
+		}
+
+		// A dangling link could still be written through to wherever it points.
+		if _, err := os.Lstat(existing); err == nil {
+			return "", newError(PATH_ESCAPES_ROOT, name)
+		}
+
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing, rest = parent, filepath.Join(filepath.Base(existing), rest)
+	}
+
+	rel, err := filepath.Rel(sb.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", newError(PATH_ESCAPES_ROOT, name)
+	}
+
+	return path, nil
+}
+
+// Returns the error of a failed file operation, naming the path as the program gave it rather than the host's.
+func fsError(path object.Object, err error) *object.Error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+
+	return newError(FS_ERROR, path.(*object.String).Value, err)
+}
@@ -0,0 +1,276 @@
+package eval
+
+import (
+	"sort"
+
+	"github.com/MohamTahaB/interpreter-go/object"
+	"github.com/MohamTahaB/interpreter-go/token"
+)
+
+// Builtins over arrays calling back into functions of the program. An error raised by a callback is returned as is.
+var FUNCTIONAL_BUILTINS = []*object.Builtin{
+	{Name: "map", Fn: builtinMap},
+	{Name: "filter", Fn: builtinFilter},
+	{Name: "reduce", Fn: builtinReduce},
+	{Name: "any", Fn: builtinAny},
+	{Name: "all", Fn: builtinAll},
+	{Name: "sort", Fn: builtinSort},
+	{Name: "zip", Fn: builtinZip},
+	{Name: "range", Fn: builtinRange},
+	{Name: "enumerate", Fn: builtinEnumerate},
+}
+
+// map(array, fn): the array of fn(element) for each element.
+func builtinMap(args ...object.Object) object.Object {
+	if err := checkCallbackArgs("map", args, 2, 2); err != nil {
+		return err
+	}
+
+	elements := args[0].(*object.Array).Elements
+	mapped := make([]object.Object, len(elements))
+	for idx, element := range elements {
+		result := callBack(args[1], element)
+		if isError(result) {
+			return result
+		}
+		mapped[idx] = result
+	}
+
+	return &object.Array{Elements: mapped}
+}
+
+// filter(array, fn): the array of the elements fn returns a truthy value for.
+func builtinFilter(args ...object.Object) object.Object {
+	if err := checkCallbackArgs("filter", args, 2, 2); err != nil {
+		return err
+	}
+
+	kept := []object.Object{}
+	for _, element := range args[0].(*object.Array).Elements {
+		result := callBack(args[1], element)
+		if isError(result) {
+			return result
+		}
+		if result.Truthy() {
+			kept = append(kept, element)
+		}
+	}
+
+	return &object.Array{Elements: kept}
+}
+
+// reduce(array, fn, initial?): the accumulator fn(accumulator, element) returns once called on each element in turn.
+// It starts as the initial value, or as the first element without one.
+func builtinReduce(args ...object.Object) object.Object {
+	if err := checkCallbackArgs("reduce", args, 2, 3); err != nil {
+		return err
+	}
+
+	elements := args[0].(*object.Array).Elements
+	var accumulator object.Object
+	switch {
+	case len(args) == 3:
+		accumulator = args[2]
+	case len(elements) == 0:
+		return newError(EMPTY_ARRAY, "reduce")
+	default:
+		accumulator, elements = elements[0], elements[1:]
+	}
+
+	for _, element := range elements {
+		accumulator = callBack(args[1], accumulator, element)
+		if isError(accumulator) {
+			return accumulator
+		}
+	}
+
+	return accumulator
+}
+
+// any(array, fn?): whether fn returns a truthy value for some element, or some element is truthy without fn.
+func builtinAny(args ...object.Object) object.Object {
+	return quantify("any", args, true)
+}
+
+// all(array, fn?): whether fn returns a truthy value for every element, or every element is truthy without fn.
+func builtinAll(args ...object.Object) object.Object {
+	return quantify("all", args, false)
+}
+
+// Returns whether some element satisfies the predicate if some is true, whether all of them do otherwise.
+// It stops at the first element that decides the outcome.
+func quantify(name string, args []object.Object, some bool) object.Object {
+	if err := checkCallbackArgs(name, args, 1, 2); err != nil {
+		return err
+	}
+
+	for _, element := range args[0].(*object.Array).Elements {
+		result := element
+		if len(args) == 2 {
+			if result = callBack(args[1], element); isError(result) {
+				return result
+			}
+		}
+
+		if result.Truthy() == some {
+			return nativeBoolToBooleanObject(some)
+		}
+	}
+
+	return nativeBoolToBooleanObject(!some)
+}
+
+// sort(array, fn?): a sorted copy of the array, in which fn(a, b) is truthy if a goes before b, or a < b without fn.
+// The sort is stable, and the first error a comparison raises stops it.
+func builtinSort(args ...object.Object) object.Object {
+	if err := checkCallbackArgs("sort", args, 1, 2); err != nil {
+		return err
+	}
+
+	less := func(a, b object.Object) object.Object {
+		return evalInfixExpression(a, b, token.LT, token.Token{})
+	}
+	if len(args) == 2 {
+		less = func(a, b object.Object) object.Object {
+			return callBack(args[1], a, b)
+		}
+	}
+
+	sorted := append([]object.Object{}, args[0].(*object.Array).Elements...)
+	var err object.Object
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+
+		result := less(sorted[i], sorted[j])
+		if isError(result) {
+			err = result
+			return false
+		}
+		return result.Truthy()
+	})
+
+	if err != nil {
+		return err
+	}
+	return &object.Array{Elements: sorted}
+}
+
+// zip(arrays...): the array of the arrays of the elements at each index, as long as the shortest array.
+func builtinZip(args ...object.Object) object.Object {
+	if len(args) == 0 {
+		return newError(WRONG_ARGS_NB, 1, 0)
+	}
+
+	length := -1
+	for idx, arg := range args {
+		array, ok := arg.(*object.Array)
+		if !ok {
+			return newError(WRONG_ARG_TYPE, idx+1, "zip", object.ARRAY_OBJ, arg.Type())
+		}
+		if length < 0 || len(array.Elements) < length {
+			length = len(array.Elements)
+		}
+	}
+
+	zipped := make([]object.Object, length)
+	for idx := range zipped {
+		tuple := make([]object.Object, len(args))
+		for pos, arg := range args {
+			tuple[pos] = arg.(*object.Array).Elements[idx]
+		}
+		zipped[idx] = &object.Array{Elements: tuple}
+	}
+
+	return &object.Array{Elements: zipped}
+}
+
+// Number of elements past which range refuses to build an array, which would exhaust memory.
+const MAX_RANGE_LENGTH = 1 << 24
+
+// range(end), range(start, end, step?): the array of the integers from start, 0 by default, up to end excluded, step apart, 1 by default.
+func builtinRange(args ...object.Object) object.Object {
+	if err := checkArgs("range", args, 1, object.INTEGER_OBJ, object.INTEGER_OBJ, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	bounds := make([]int64, len(args))
+	for idx, arg := range args {
+		bounds[idx] = arg.(*object.Integer).Value
+	}
+
+	start, end, step := int64(0), bounds[0], int64(1)
+	if len(bounds) > 1 {
+		start, end = bounds[0], bounds[1]
+	}
+	if len(bounds) > 2 {
+		step = bounds[2]
+	}
+
+	if step == 0 {
+		return newError(ZERO_STEP)
+	}
+
+	// The count is computed unsigned, as the distance between the bounds may not fit in an int64.
+	count := uint64(0)
+	if step > 0 && start < end {
+		count = (uint64(end)-uint64(start)-1)/uint64(step) + 1
+	}
+	if step < 0 && start > end {
+		count = (uint64(start)-uint64(end)-1)/(-uint64(step)) + 1
+	}
+	if count > MAX_RANGE_LENGTH {
+		return newError(RANGE_TOO_LARGE, count)
+	}
+
+	elements := make([]object.Object, count)
+	for idx := range elements {
+		elements[idx] = object.NewInteger(start + int64(idx)*step)
+	}
+
+	return &object.Array{Elements: elements}
+}
+
+// enumerate(array): the array of the [index, element] pairs of the array.
+func builtinEnumerate(args ...object.Object) object.Object {
+	if err := checkArgs("enumerate", args, 1, object.ARRAY_OBJ); err != nil {
+		return err
+	}
+
+	elements := args[0].(*object.Array).Elements
+	pairs := make([]object.Object, len(elements))
+	for idx, element := range elements {
+		pairs[idx] = &object.Array{Elements: []object.Object{object.NewInteger(int64(idx)), element}}
+	}
+
+	return &object.Array{Elements: pairs}
+}
+
+// Checks the arguments of a builtin taking an array, then a function, then any other argument, the optional ones last.
+func checkCallbackArgs(name string, args []object.Object, required, allowed int) *object.Error {
+	types := []object.ObjectType{object.ARRAY_OBJ, object.ANY_OBJ, object.ANY_OBJ}[:allowed]
+	if err := checkArgs(name, args, required, types...); err != nil {
+		return err
+	}
+
+	if len(args) > 1 && !isCallable(args[1]) {
+		return newError(WRONG_ARG_TYPE, 2, name, object.FUNCTION_OBJ, args[1].Type())
+	}
+
+	return nil
+}
+
+func isCallable(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Function, *object.Builtin, *object.Class:
+		return true
+	}
+
+	return false
+}
+
+// Calls the function of the program back. The call is made by the interpreter, at no position.
+func callBack(fn object.Object, args ...object.Object) object.Object {
+	return applyFunction(fn, args, token.Token{})
+}
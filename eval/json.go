@@ -0,0 +1,232 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/MohamTahaB/interpreter-go/object"
+)
+
+// Functions of the json module.
+var JSON_BUILTINS = []*object.Builtin{
+	{Name: "parse", Fn: jsonParse},
+	{Name: "stringify", Fn: jsonStringify},
+}
+
+// parse(s): the value the JSON text encodes, objects as hashes keeping the order of their keys. Numbers must be integers.
+func jsonParse(args ...object.Object) object.Object {
+	if err := checkArgs("json.parse", args, 1, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(args[0].(*object.String).Value))
+	decoder.UseNumber()
+
+	value := decodeJSON(decoder)
+	if isError(value) {
+		return value
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		if err == nil {
+			return newError(INVALID_JSON, "unexpected data after the value")
+		}
+		return newError(INVALID_JSON, err)
+	}
+
+	return value
+}
+
+// Decodes the value starting at the next token of the decoder.
+func decodeJSON(decoder *json.Decoder) object.Object {
+	tok, err := decoder.Token()
+	if errors.Is(err, io.EOF) {
+		return newError(INVALID_JSON, "unexpected end of input")
+	}
+	if err != nil {
+		return newError(INVALID_JSON, err)
+	}
+
+	switch tok := tok.(type) {
+	case nil:
+		return NULL
+	case bool:
+		return nativeBoolToBooleanObject(tok)
+	case string:
+		return &object.String{Value: tok}
+	case json.Number:
+		value, ok := new(big.Int).SetString(tok.String(), 10)
+		if !ok {
+			return newError(JSON_NOT_INTEGER, tok)
+		}
+		return object.NewBigInteger(value)
+	}
+
+	if tok == json.Delim('[') {
+		elements := []object.Object{}
+		for decoder.More() {
+			element := decodeJSON(decoder)
+			if isError(element) {
+				return element
+			}
+			elements = append(elements, element)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return newError(INVALID_JSON, "unexpected end of input")
+		}
+		return &object.Array{Elements: elements}
+	}
+
+	hash := object.NewHash()
+	for decoder.More() {
+		key := decodeJSON(decoder)
+		if isError(key) {
+			return key
+		}
+		value := decodeJSON(decoder)
+		if isError(value) {
+			return value
+		}
+		hash.Set(key.(*object.String), value)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return newError(INVALID_JSON, "unexpected end of input")
+	}
+
+	return hash
+}
+
+// Number of spaces past which json.stringify refuses to indent a level.
+const MAX_INDENT = 10
+
+// stringify(value, indent?): the JSON text of the value, on a single line, or indented by that many spaces per level.
+// Hashes and instances become objects, hashes only if their keys are strings. Functions and cyclic values cannot be serialized.
+func jsonStringify(args ...object.Object) object.Object {
+	if err := checkArgs("json.stringify", args, 1, object.ANY_OBJ, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := encodeJSON(&out, args[0], map[object.Object]bool{}); err != nil {
+		return err
+	}
+
+	if len(args) == 2 {
+		indent := args[1].(*object.Integer).Value
+		if indent < 0 {
+			return newError(NEGATIVE_INDENT, indent)
+		}
+		if indent > MAX_INDENT {
+			return newError(INDENT_TOO_LARGE, indent, MAX_INDENT)
+		}
+
+		if indent > 0 {
+			var indented bytes.Buffer
+			if err := json.Indent(&indented, out.Bytes(), "", strings.Repeat(" ", int(indent))); err != nil {
+				return newError(INVALID_JSON, err)
+			}
+			return &object.String{Value: indented.String()}
+		}
+	}
+
+	return &object.String{Value: out.String()}
+}
+
+// Writes the JSON text of the value. Open holds the values being written, which the value must not be one of.
+func encodeJSON(out *bytes.Buffer, value object.Object, open map[object.Object]bool) *object.Error {
+	switch value := value.(type) {
+	case *object.Null:
+		out.WriteString("null")
+		return nil
+	case *object.Boolean, *object.Integer, *object.BigInteger:
+		out.WriteString(value.Inspect())
+		return nil
+	case *object.String:
+		writeJSONString(out, value.Value)
+		return nil
+	}
+
+	if open[value] {
+		return newError(CYCLIC_VALUE, value.Type())
+	}
+	open[value] = true
+	defer delete(open, value)
+
+	switch value := value.(type) {
+	case *object.Array:
+		out.WriteByte('[')
+		for idx, element := range value.Elements {
+			if idx > 0 {
+				out.WriteByte(',')
+			}
+			if err := encodeJSON(out, element, open); err != nil {
+				return err
+			}
+		}
+		out.WriteByte(']')
+		return nil
+
+	case *object.Hash:
+		keys, values := []string{}, []object.Object{}
+		for _, pair := range value.Entries() {
+			key, ok := pair.Key.(*object.String)
+			if !ok {
+				return newError(JSON_KEY_NOT_STRING, pair.Key.Type())
+			}
+			keys, values = append(keys, key.Value), append(values, pair.Value)
+		}
+		return encodeJSONObject(out, keys, values, open)
+
+	case *object.StructInstance:
+		values := []object.Object{}
+		for _, field := range value.Struct.Fields {
+			values = append(values, value.Fields[field])
+		}
+		return encodeJSONObject(out, value.Struct.Fields, values, open)
+
+	case *object.Instance:
+		keys := make([]string, 0, len(value.Fields))
+		for name := range value.Fields {
+			keys = append(keys, name)
+		}
+		sort.Strings(keys)
+
+		values := []object.Object{}
+		for _, name := range keys {
+			values = append(values, value.Fields[name])
+		}
+		return encodeJSONObject(out, keys, values, open)
+	}
+
+	return newError(NOT_SERIALIZABLE, value.Type())
+}
+
+func encodeJSONObject(out *bytes.Buffer, keys []string, values []object.Object, open map[object.Object]bool) *object.Error {
+	out.WriteByte('{')
+	for idx, key := range keys {
+		if idx > 0 {
+			out.WriteByte(',')
+		}
+		writeJSONString(out, key)
+		out.WriteByte(':')
+		if err := encodeJSON(out, values[idx], open); err != nil {
+			return err
+		}
+	}
+	out.WriteByte('}')
+
+	return nil
+}
+
+// Writes the string quoted and escaped, leaving <, > and & as they are.
+func writeJSONString(out *bytes.Buffer, s string) {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	out.Truncate(out.Len() - 1)
+}
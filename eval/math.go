@@ -0,0 +1,159 @@
+package eval
+
+import (
+	"math/big"
+
+	"github.com/MohamTahaB/interpreter-go/object"
+)
+
+// Functions of the math module. They take and return integers, big or not.
+var MATH_BUILTINS = []*object.Builtin{
+	{Name: "abs", Fn: mathAbs},
+	{Name: "min", Fn: mathMin},
+	{Name: "max", Fn: mathMax},
+	{Name: "pow", Fn: mathPow},
+	{Name: "sqrt", Fn: mathSqrt},
+	{Name: "floor", Fn: mathFloor},
+	{Name: "gcd", Fn: mathGcd},
+}
+
+func mathAbs(args ...object.Object) object.Object {
+	values, err := integerArgs("math.abs", args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	return object.NewBigInteger(values[0].Abs(values[0]))
+}
+
+// min(values...): the least of the integers, given as arguments or as a single array.
+func mathMin(args ...object.Object) object.Object {
+	return extremum("math.min", args, -1)
+}
+
+// max(values...): the greatest of the integers, given as arguments or as a single array.
+func mathMax(args ...object.Object) object.Object {
+	return extremum("math.max", args, 1)
+}
+
+// Size in bits past which math.pow refuses to raise a number to a power, which would take too long and too much memory.
+const MAX_POWER_BITS = 1 << 20
+
+// pow(base, exponent): base raised to the non-negative exponent.
+func mathPow(args ...object.Object) object.Object {
+	values, err := integerArgs("math.pow", args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	if values[1].Sign() < 0 {
+		return newError(NEGATIVE_EXPONENT, values[1])
+	}
+
+	// Powers of 0, 1 and -1 stay small, any other base has at most its size times the exponent in bits.
+	if values[0].CmpAbs(big.NewInt(1)) > 0 {
+		bits := new(big.Int).Mul(big.NewInt(int64(values[0].BitLen())), values[1])
+		if bits.Cmp(big.NewInt(MAX_POWER_BITS)) > 0 {
+			return newError(POWER_TOO_LARGE, values[0], values[1])
+		}
+	}
+
+	return object.NewBigInteger(values[0].Exp(values[0], values[1], nil))
+}
+
+// sqrt(x): the square root of the non-negative x, rounded down.
+func mathSqrt(args ...object.Object) object.Object {
+	values, err := integerArgs("math.sqrt", args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	if values[0].Sign() < 0 {
+		return newError(NEGATIVE_SQRT, values[0])
+	}
+
+	return object.NewBigInteger(values[0].Sqrt(values[0]))
+}
+
+// floor(x, divisor): x divided by the divisor, rounded toward negative infinity, where x / divisor rounds toward zero.
+func mathFloor(args ...object.Object) object.Object {
+	values, err := integerArgs("math.floor", args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	x, divisor := values[0], values[1]
+	if divisor.Sign() == 0 {
+		return newError(DIVISION_BY_ZERO)
+	}
+
+	quotient, remainder := new(big.Int).QuoRem(x, divisor, new(big.Int))
+	if remainder.Sign() != 0 && remainder.Sign() != divisor.Sign() {
+		quotient.Sub(quotient, big.NewInt(1))
+	}
+
+	return object.NewBigInteger(quotient)
+}
+
+// gcd(a, b): the greatest common divisor of a and b, never negative, 0 only if both are.
+func mathGcd(args ...object.Object) object.Object {
+	values, err := integerArgs("math.gcd", args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	a, b := values[0].Abs(values[0]), values[1].Abs(values[1])
+	return object.NewBigInteger(new(big.Int).GCD(nil, nil, a, b))
+}
+
+// Returns the least integer of the arguments if sign is -1, the greatest if it is 1.
+func extremum(name string, args []object.Object, sign int) object.Object {
+	if len(args) == 1 {
+		if array, ok := args[0].(*object.Array); ok {
+			for idx, element := range array.Elements {
+				if _, ok := object.ToBigInt(element); !ok {
+					return newError(WRONG_ELEMENT_TYPE, idx, 1, name, object.INTEGER_OBJ, element.Type())
+				}
+			}
+			if len(array.Elements) == 0 {
+				return newError(EMPTY_ARRAY, name)
+			}
+			args = array.Elements
+		}
+	}
+
+	values, err := integerArgs(name, args, 1, max(len(args), 1))
+	if err != nil {
+		return err
+	}
+
+	best := 0
+	for idx, value := range values {
+		if value.Cmp(values[best]) == sign {
+			best = idx
+		}
+	}
+
+	return args[best]
+}
+
+// Checks that there are between required and allowed arguments, all of them integers, and returns their values.
+func integerArgs(name string, args []object.Object, required, allowed int) ([]*big.Int, *object.Error) {
+	switch {
+	case required == allowed && len(args) != required:
+		return nil, newError(WRONG_ARGS_NB, required, len(args))
+	case len(args) < required || len(args) > allowed:
+		return nil, newError(WRONG_ARGS_RANGE, required, allowed, len(args))
+	}
+
+	values := make([]*big.Int, len(args))
+	for idx, arg := range args {
+		value, ok := object.ToBigInt(arg)
+		if !ok {
+			return nil, newError(WRONG_ARG_TYPE, idx+1, name, object.INTEGER_OBJ, arg.Type())
+		}
+		values[idx] = value
+	}
+
+	return values, nil
+}
@@ -0,0 +1,208 @@
+package eval
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MohamTahaB/interpreter-go/ast"
+	"github.com/MohamTahaB/interpreter-go/lexer"
+	"github.com/MohamTahaB/interpreter-go/object"
+	"github.com/MohamTahaB/interpreter-go/parser"
+	"github.com/MohamTahaB/interpreter-go/resolver"
+	"github.com/MohamTahaB/interpreter-go/token"
+)
+
+// Extension of module files, added to an import path that has none.
+const MODULE_EXTENSION = ".mky"
+
+// Name of a module not read from a file, such as the REPL's.
+const MAIN_MODULE = "main"
+
+// Name of the trace frame of a module's evaluation.
+const MODULE_FRAME = "<module %s>"
+
+// Loads the modules of a program from their files, evaluating each of them once, the first time it is imported.
+type Loader struct {
+	// Directories searched in order for an import path that is neither absolute nor relative, once the importing module's own has been.
+	SearchPath []string
+
+	// Modules evaluated so far, by absolute path.
+	modules map[string]*object.Module
+
+	// Modules the host provides, such as fs, imported by their bare name as builtin modules are.
+	provided map[string]*object.Module
+
+	// Paths of the modules being evaluated, outermost first, an import of any of them closing a cycle.
+	loading []string
+}
+
+// Returns a loader providing the time module with the system clock, and the random module seeded from it.
+func NewLoader(searchPath ...string) *Loader {
+	l := &Loader{SearchPath: searchPath, modules: make(map[string]*object.Module), provided: make(map[string]*object.Module)}
+	l.Provide(NewTimeModule(SystemClock{}))
+	l.Provide(NewRandomModule(time.Now().UnixNano()))
+
+	return l
+}
+
+// Makes the module importable by its name from any module the loader loads, in place of any module it provided under that name.
+func (l *Loader) Provide(module *object.Module) {
+	l.provided[module.Name] = module
+}
+
+// Returns the module a program runs as, read from the file at the path, or not read from a file if the path is empty.
+// It is never done evaluating as far as imports go, so that importing it back is a cycle.
+func (l *Loader) Main(path string) *object.Module {
+	if path == "" {
+		return object.NewModule(MAIN_MODULE, "", l)
+	}
+
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	l.loading = append(l.loading, path)
+
+	return object.NewModule(moduleName(path), path, l)
+}
+
+// Returns the module at the path, evaluating it in an environment of its own unless it already was.
+func (l *Loader) Import(path string, importer *object.Module, at token.Token) (*object.Module, *object.Error) {
+	if module, ok := l.provided[path]; ok {
+		return module, nil
+	}
+
+	file, err := l.resolve(path, importer)
+	if err != nil {
+		return nil, err
+	}
+
+	if module, ok := l.modules[file]; ok {
+		return module, nil
+	}
+
+	for idx, loading := range l.loading {
+		if loading == file {
+			cycle := []string{}
+			for _, name := range append(l.loading[idx:], file) {
+				cycle = append(cycle, filepath.Base(name))
+			}
+			return nil, newError(IMPORT_CYCLE, strings.Join(cycle, " -> "))
+		}
+	}
+
+	program, err := parseModule(path, file)
+	if err != nil {
+		return nil, err
+	}
+
+	module := object.NewModule(moduleName(file), file, l)
+
+	l.loading = append(l.loading, file)
+	result := Eval(program, module.Env)
+	l.loading = l.loading[:len(l.loading)-1]
+
+	if errObj, ok := result.(*object.Error); ok {
+		errObj.AddFrame(fmt.Sprintf(MODULE_FRAME, module.Name), at)
+		return nil, errObj
+	}
+
+	l.modules[file] = module
+	return module, nil
+}
+
+// Returns the absolute path of the module file an import path stands for.
+// A path starting with ./ or ../ is relative to the importing module's directory only, any other relative path is also looked for in the search path.
+func (l *Loader) resolve(path string, importer *object.Module) (string, *object.Error) {
+	name := filepath.FromSlash(path)
+	if filepath.Ext(name) == "" {
+		name += MODULE_EXTENSION
+	}
+
+	dir := "."
+	if importer != nil && importer.Path != "" {
+		dir = filepath.Dir(importer.Path)
+	}
+
+	dirs := []string{dir}
+	switch {
+	case filepath.IsAbs(name):
+		dirs = []string{""}
+	case !strings.HasPrefix(path, "./") && !strings.HasPrefix(path, "../"):
+		dirs = append(dirs, l.SearchPath...)
+	}
+
+	for _, dir := range dirs {
+		candidate, err := filepath.Abs(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+
+	return "", newError(MODULE_NOT_FOUND, path)
+}
+
+func parseModule(path, file string) (*ast.Program, *object.Error) {
+	source, err := os.ReadFile(file)
+	if err != nil {
+		return nil, newError(MODULE_UNREADABLE, path, err)
+	}
+
+	p := parser.New(lexer.New(string(source)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, newError(MODULE_PARSE_ERRORS, path, strings.Join(p.Errors(), "; "))
+	}
+
+	resolver.Resolve(program)
+	return program, nil
+}
+
+// Modules are named after their file, extension left out.
+func moduleName(file string) string {
+	base := filepath.Base(file)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Binds the imported module to the import's name. Builtin modules can be imported from anywhere.
+func evalImportStatement(node *ast.ImportStatement, env *object.Environment) *object.Error {
+	if module, ok := lookupBuiltinModule(node.Path); ok {
+		return bindIdentifier(node.Name, module, env, false)
+	}
+
+	importer := env.Module()
+	if importer == nil || importer.Importer == nil {
+		return newError(IMPORTS_UNAVAILABLE)
+	}
+
+	module, err := importer.Importer.Import(node.Path, importer, node.Token)
+	if err != nil {
+		return err
+	}
+
+	return bindIdentifier(node.Name, module, env, false)
+}
+
+// Evaluates the declaration, then exposes the names it binds as members of the module.
+func evalExportStatement(node *ast.ExportStatement, env *object.Environment) object.Object {
+	if !env.IsModule() {
+		return newError(EXPORT_NOT_TOP_LEVEL)
+	}
+
+	if result := Eval(node.Declaration, env); isError(result) {
+		return result
+	}
+
+	module := env.Module()
+	for _, name := range node.Names() {
+		module.Export(name.Value)
+	}
+
+	return NULL
+}
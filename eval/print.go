@@ -0,0 +1,113 @@
+package eval
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/MohamTahaB/interpreter-go/object"
+)
+
+// Builtins formatting values without printing them.
+var FORMATTING_BUILTINS = []*object.Builtin{
+	{Name: "sprintf", Fn: builtinSprintf},
+}
+
+// Where print, println and printf write, the standard output unless the host sets another before running programs.
+var Output io.Writer = os.Stdout
+
+// Builtins printing to Output.
+var OUTPUT_BUILTINS = []*object.Builtin{
+	// print(values...): writes the values, a space in between.
+	{Name: "print", Fn: func(args ...object.Object) object.Object {
+		return write(joinValues(args))
+	}},
+	// println(values...): writes the values, a space in between, then a newline.
+	{Name: "println", Fn: func(args ...object.Object) object.Object {
+		return write(joinValues(args) + "\n")
+	}},
+	// printf(format, values...): writes the values as sprintf formats them.
+	{Name: "printf", Fn: func(args ...object.Object) object.Object {
+		formatted := builtinSprintf(args...)
+		if isError(formatted) {
+			return formatted
+		}
+		return write(formatted.(*object.String).Value)
+	}},
+}
+
+func write(s string) object.Object {
+	if _, err := io.WriteString(Output, s); err != nil {
+		return newError(OUTPUT_ERROR, err)
+	}
+	return NULL
+}
+
+// sprintf(format, values...): the format with each verb replaced by the next value.
+// %s and %v write a value as it prints, %q quotes it, %d writes an integer, and %% a percent sign.
+func builtinSprintf(args ...object.Object) object.Object {
+	if len(args) == 0 {
+		return newError(WRONG_ARGS_NB, 1, 0)
+	}
+	if err := checkArgs("sprintf", args[:1], 1, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	format, values := args[0].(*object.String).Value, args[1:]
+
+	var out strings.Builder
+	next := 0
+	for idx := 0; idx < len(format); idx++ {
+		if format[idx] != '%' {
+			out.WriteByte(format[idx])
+			continue
+		}
+
+		idx++
+		if idx == len(format) {
+			return newError(UNFINISHED_VERB)
+		}
+
+		verb := format[idx]
+		if verb == '%' {
+			out.WriteByte('%')
+			continue
+		}
+
+		if next >= len(values) {
+			return newError(MISSING_FORMAT_ARG, next)
+		}
+		value := values[next]
+		next++
+
+		switch verb {
+		case 's', 'v':
+			out.WriteString(value.Inspect())
+		case 'q':
+			out.WriteString(strconv.Quote(value.Inspect()))
+		case 'd':
+			if _, ok := object.ToBigInt(value); !ok {
+				return newError(WRONG_VERB_ARG, "d", object.INTEGER_OBJ, value.Type())
+			}
+			out.WriteString(value.Inspect())
+		default:
+			return newError(UNKNOWN_VERB, string(verb))
+		}
+	}
+
+	if next < len(values) {
+		return newError(EXTRA_FORMAT_ARGS, len(values)-next)
+	}
+
+	return &object.String{Value: out.String()}
+}
+
+func joinValues(values []object.Object) string {
+	parts := make([]string, len(values))
+	for idx, value := range values {
+		parts[idx] = value.Inspect()
+	}
+
+	return strings.Join(parts, " ")
+}
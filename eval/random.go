@@ -0,0 +1,68 @@
+package eval
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/MohamTahaB/interpreter-go/object"
+)
+
+// Returns the random module, drawing from a source seeded with the seed, so that the same seed gives the same draws.
+func NewRandomModule(seed int64) *object.Module {
+	rng := rand.New(rand.NewSource(seed))
+
+	return object.NewBuiltinModule("random",
+		// int(max), int(min, max): an integer from min, 0 by default, up to max excluded.
+		&object.Builtin{Name: "int", Fn: func(args ...object.Object) object.Object {
+			if err := checkArgs("random.int", args, 1, object.INTEGER_OBJ, object.INTEGER_OBJ); err != nil {
+				return err
+			}
+
+			low, high := int64(0), args[0].(*object.Integer).Value
+			if len(args) == 2 {
+				low, high = high, args[1].(*object.Integer).Value
+			}
+			if high <= low {
+				return newError(EMPTY_RANGE, low, high)
+			}
+
+			// A span too wide for an int64 covers more than half of the uint64s, so few draws are rejected.
+			span := uint64(high) - uint64(low)
+			if span > math.MaxInt64 {
+				for {
+					if offset := rng.Uint64(); offset < span {
+						return object.NewInteger(int64(uint64(low) + offset))
+					}
+				}
+			}
+
+			return object.NewInteger(low + rng.Int63n(int64(span)))
+		}},
+		// choice(array): an element of the array.
+		&object.Builtin{Name: "choice", Fn: func(args ...object.Object) object.Object {
+			if err := checkArgs("random.choice", args, 1, object.ARRAY_OBJ); err != nil {
+				return err
+			}
+
+			elements := args[0].(*object.Array).Elements
+			if len(elements) == 0 {
+				return newError(EMPTY_ARRAY, "random.choice")
+			}
+
+			return elements[rng.Intn(len(elements))]
+		}},
+		// shuffle(array): a copy of the array, its elements in random order.
+		&object.Builtin{Name: "shuffle", Fn: func(args ...object.Object) object.Object {
+			if err := checkArgs("random.shuffle", args, 1, object.ARRAY_OBJ); err != nil {
+				return err
+			}
+
+			shuffled := append([]object.Object{}, args[0].(*object.Array).Elements...)
+			rng.Shuffle(len(shuffled), func(i, j int) {
+				shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+			})
+
+			return &object.Array{Elements: shuffled}
+		}},
+	)
+}
@@ -0,0 +1,130 @@
+package eval
+
+import (
+	"github.com/MohamTahaB/interpreter-go/ast"
+	"github.com/MohamTahaB/interpreter-go/object"
+)
+
+// Functions of the re module. They take a regex, or a string compiled as one without flags.
+var RE_BUILTINS = []*object.Builtin{
+	{Name: "compile", Fn: reCompile},
+	{Name: "match", Fn: reMatch},
+	{Name: "findAll", Fn: reFindAll},
+	{Name: "replace", Fn: reReplace},
+	{Name: "split", Fn: reSplit},
+}
+
+// compile(pattern, flags?): the regex of the pattern, as /pattern/flags would be.
+func reCompile(args ...object.Object) object.Object {
+	if err := checkArgs("re.compile", args, 1, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	flags := ""
+	if len(args) == 2 {
+		flags = args[1].(*object.String).Value
+	}
+
+	return newRegex(args[0].(*object.String).Value, flags)
+}
+
+// match(re, s): the first match of the regex in s followed by its groups, a group that matched nothing being null. Null without a match.
+func reMatch(args ...object.Object) object.Object {
+	regex, errObj := regexArgs("re.match", args, object.STRING_OBJ)
+	if errObj != nil {
+		return errObj
+	}
+
+	s := args[1].(*object.String).Value
+	indexes := regex.Value.FindStringSubmatchIndex(s)
+	if indexes == nil {
+		return NULL
+	}
+
+	groups := make([]object.Object, len(indexes)/2)
+	for idx := range groups {
+		start, end := indexes[2*idx], indexes[2*idx+1]
+		if start < 0 {
+			groups[idx] = NULL
+			continue
+		}
+		groups[idx] = &object.String{Value: s[start:end]}
+	}
+
+	return &object.Array{Elements: groups}
+}
+
+// findAll(re, s): the successive matches of the regex in s.
+func reFindAll(args ...object.Object) object.Object {
+	regex, errObj := regexArgs("re.findAll", args, object.STRING_OBJ)
+	if errObj != nil {
+		return errObj
+	}
+
+	return stringArray(regex.Value.FindAllString(args[1].(*object.String).Value, -1))
+}
+
+// replace(re, s, replacement): s with each match of the regex replaced, $1 or ${name} in the replacement standing for a group.
+func reReplace(args ...object.Object) object.Object {
+	regex, errObj := regexArgs("re.replace", args, object.STRING_OBJ, object.STRING_OBJ)
+	if errObj != nil {
+		return errObj
+	}
+
+	s, replacement := args[1].(*object.String).Value, args[2].(*object.String).Value
+	return &object.String{Value: regex.Value.ReplaceAllString(s, replacement)}
+}
+
+// split(re, s): the parts of s around each match of the regex.
+func reSplit(args ...object.Object) object.Object {
+	regex, errObj := regexArgs("re.split", args, object.STRING_OBJ)
+	if errObj != nil {
+		return errObj
+	}
+
+	return stringArray(regex.Value.Split(args[1].(*object.String).Value, -1))
+}
+
+// Checks the arguments of a function taking a regex, then arguments of the types, and returns the regex.
+func regexArgs(name string, args []object.Object, types ...object.ObjectType) (*object.Regex, *object.Error) {
+	types = append([]object.ObjectType{object.ANY_OBJ}, types...)
+	if err := checkArgs(name, args, len(types), types...); err != nil {
+		return nil, err
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Regex:
+		return arg, nil
+	case *object.String:
+		regex := newRegex(arg.Value, "")
+		if errObj, ok := regex.(*object.Error); ok {
+			return nil, errObj
+		}
+		return regex.(*object.Regex), nil
+	}
+
+	return nil, newError(WRONG_ARG_TYPE, 1, name, object.REGEX_OBJ, args[0].Type())
+}
+
+// The pattern of the literal is compiled once and kept on the node. An invalid one is reported each time.
+func evalRegexLiteral(node *ast.RegexLiteral) object.Object {
+	if node.Compiled == nil {
+		regex := newRegex(node.Pattern, node.Flags)
+		if isError(regex) {
+			return regex
+		}
+		node.Compiled = regex.(*object.Regex).Value
+	}
+
+	return &object.Regex{Pattern: node.Pattern, Flags: node.Flags, Value: node.Compiled}
+}
+
+// Returns the compiled regex, or the error telling why the pattern or the flags are invalid.
+func newRegex(pattern, flags string) object.Object {
+	regex, err := object.NewRegex(pattern, flags)
+	if err != nil {
+		return newError(INVALID_REGEX, pattern, flags, err)
+	}
+
+	return regex
+}
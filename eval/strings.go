@@ -0,0 +1,251 @@
+package eval
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/MohamTahaB/interpreter-go/object"
+)
+
+// Functions of the strings module. Positions and lengths count characters, not bytes.
+var STRINGS_BUILTINS = []*object.Builtin{
+	{Name: "split", Fn: stringsSplit},
+	{Name: "join", Fn: stringsJoin},
+	{Name: "trim", Fn: stringsTrim},
+	{Name: "upper", Fn: stringsUpper},
+	{Name: "lower", Fn: stringsLower},
+	{Name: "replace", Fn: stringsReplace},
+	{Name: "contains", Fn: stringsContains},
+	{Name: "index", Fn: stringsIndex},
+	{Name: "startsWith", Fn: stringsStartsWith},
+	{Name: "endsWith", Fn: stringsEndsWith},
+	{Name: "repeat", Fn: stringsRepeat},
+	{Name: "substr", Fn: stringsSubstr},
+	{Name: "format", Fn: stringsFormat},
+}
+
+// split(s, sep?): the parts of s around each sep, or around runs of whitespace without one.
+func stringsSplit(args ...object.Object) object.Object {
+	if err := checkArgs("strings.split", args, 1, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	s := args[0].(*object.String).Value
+	if len(args) == 1 {
+		return stringArray(strings.Fields(s))
+	}
+
+	return stringArray(strings.Split(s, args[1].(*object.String).Value))
+}
+
+// join(parts, sep?): the strings of the array, sep in between.
+func stringsJoin(args ...object.Object) object.Object {
+	if err := checkArgs("strings.join", args, 1, object.ARRAY_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	parts := []string{}
+	for idx, element := range args[0].(*object.Array).Elements {
+		str, ok := element.(*object.String)
+		if !ok {
+			return newError(WRONG_ELEMENT_TYPE, idx, 1, "strings.join", object.STRING_OBJ, element.Type())
+		}
+		parts = append(parts, str.Value)
+	}
+
+	sep := ""
+	if len(args) == 2 {
+		sep = args[1].(*object.String).Value
+	}
+
+	return &object.String{Value: strings.Join(parts, sep)}
+}
+
+// trim(s, cutset?): s without its leading and trailing characters of the cutset, or whitespace without one.
+func stringsTrim(args ...object.Object) object.Object {
+	if err := checkArgs("strings.trim", args, 1, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	s := args[0].(*object.String).Value
+	if len(args) == 1 {
+		return &object.String{Value: strings.TrimSpace(s)}
+	}
+
+	return &object.String{Value: strings.Trim(s, args[1].(*object.String).Value)}
+}
+
+func stringsUpper(args ...object.Object) object.Object {
+	if err := checkArgs("strings.upper", args, 1, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	return &object.String{Value: strings.ToUpper(args[0].(*object.String).Value)}
+}
+
+func stringsLower(args ...object.Object) object.Object {
+	if err := checkArgs("strings.lower", args, 1, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	return &object.String{Value: strings.ToLower(args[0].(*object.String).Value)}
+}
+
+// replace(s, old, new, n?): s with its first n occurrences of old replaced by new, all of them without n.
+func stringsReplace(args ...object.Object) object.Object {
+	if err := checkArgs("strings.replace", args, 3, object.STRING_OBJ, object.STRING_OBJ, object.STRING_OBJ, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	n := -1
+	if len(args) == 4 {
+		n = int(args[3].(*object.Integer).Value)
+	}
+
+	s, old, new := args[0].(*object.String).Value, args[1].(*object.String).Value, args[2].(*object.String).Value
+	return &object.String{Value: strings.Replace(s, old, new, n)}
+}
+
+func stringsContains(args ...object.Object) object.Object {
+	if err := checkArgs("strings.contains", args, 2, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	return nativeBoolToBooleanObject(strings.Contains(args[0].(*object.String).Value, args[1].(*object.String).Value))
+}
+
+// index(s, sub): position of the first occurrence of sub in s, -1 if there is none.
+func stringsIndex(args ...object.Object) object.Object {
+	if err := checkArgs("strings.index", args, 2, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	s := args[0].(*object.String).Value
+	idx := strings.Index(s, args[1].(*object.String).Value)
+	if idx < 0 {
+		return object.NewInteger(-1)
+	}
+
+	return object.NewInteger(int64(len([]rune(s[:idx]))))
+}
+
+func stringsStartsWith(args ...object.Object) object.Object {
+	if err := checkArgs("strings.startsWith", args, 2, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	return nativeBoolToBooleanObject(strings.HasPrefix(args[0].(*object.String).Value, args[1].(*object.String).Value))
+}
+
+func stringsEndsWith(args ...object.Object) object.Object {
+	if err := checkArgs("strings.endsWith", args, 2, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	return nativeBoolToBooleanObject(strings.HasSuffix(args[0].(*object.String).Value, args[1].(*object.String).Value))
+}
+
+// repeat(s, n): s n times over, as s * n.
+func stringsRepeat(args ...object.Object) object.Object {
+	if err := checkArgs("strings.repeat", args, 2, object.STRING_OBJ, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	return object.RepeatString(args[0].(*object.String).Value, args[1].(*object.Integer).Value)
+}
+
+// substr(s, start, length?): the length characters of s from start, or all of them up to its end without a length.
+// A negative start counts from the end of s. The substring is cut short at the bounds of s.
+func stringsSubstr(args ...object.Object) object.Object {
+	if err := checkArgs("strings.substr", args, 2, object.STRING_OBJ, object.INTEGER_OBJ, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	runes := []rune(args[0].(*object.String).Value)
+	size := int64(len(runes))
+
+	start := args[1].(*object.Integer).Value
+	if start < 0 {
+		start += size
+	}
+	start = min(max(start, 0), size)
+
+	end := size
+	if len(args) == 3 {
+		length := args[2].(*object.Integer).Value
+		if length < 0 {
+			return newError(NEGATIVE_LENGTH, length)
+		}
+		if length < size-start {
+			end = start + length
+		}
+	}
+
+	return &object.String{Value: string(runes[start:end])}
+}
+
+// format(template, args...): the template with each {} replaced by the next argument, and each {n} by the argument at index n.
+// Braces are written doubled, {{ and }}.
+func stringsFormat(args ...object.Object) object.Object {
+	if len(args) == 0 {
+		return newError(WRONG_ARGS_NB, 1, 0)
+	}
+	if err := checkArgs("strings.format", args[:1], 1, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	template, values := args[0].(*object.String).Value, args[1:]
+
+	var out strings.Builder
+	next := 0
+	for idx := 0; idx < len(template); idx++ {
+		ch := template[idx]
+
+		switch {
+		case (ch == '{' || ch == '}') && idx+1 < len(template) && template[idx+1] == ch:
+			out.WriteByte(ch)
+			idx++
+
+		case ch == '}':
+			return newError(UNMATCHED_FORMAT_BRACE, "}")
+
+		case ch == '{':
+			end := strings.IndexByte(template[idx:], '}')
+			if end < 0 {
+				return newError(UNMATCHED_FORMAT_BRACE, "{")
+			}
+
+			field := template[idx+1 : idx+end]
+			position := next
+			if field == "" {
+				next++
+			} else {
+				var err error
+				if position, err = strconv.Atoi(field); err != nil || position < 0 {
+					return newError(INVALID_FORMAT_FIELD, field)
+				}
+			}
+
+			if position >= len(values) {
+				return newError(MISSING_FORMAT_ARG, position)
+			}
+
+			out.WriteString(values[position].Inspect())
+			idx += end
+
+		default:
+			out.WriteByte(ch)
+		}
+	}
+
+	return &object.String{Value: out.String()}
+}
+
+func stringArray(parts []string) *object.Array {
+	elements := make([]object.Object, len(parts))
+	for idx, part := range parts {
+		elements[idx] = &object.String{Value: part}
+	}
+
+	return &object.Array{Elements: elements}
+}
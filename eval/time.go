@@ -0,0 +1,121 @@
+package eval
+
+import (
+	"math"
+	"time"
+
+	"github.com/MohamTahaB/interpreter-go/object"
+)
+
+// Source of the time module's current time and sleeps, which the host can replace to make programs reproducible.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// Clock of the system, which programs use unless the host provides another.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (SystemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// Durations in milliseconds, which the time module measures times and durations in.
+var TIME_UNITS = map[string]time.Duration{
+	"MILLISECOND": time.Millisecond,
+	"SECOND":      time.Second,
+	"MINUTE":      time.Minute,
+	"HOUR":        time.Hour,
+}
+
+// Returns the time module, reading the clock. Times are milliseconds since the Unix epoch, formatted and parsed in UTC.
+func NewTimeModule(clock Clock) *object.Module {
+	module := object.NewBuiltinModule("time",
+		// now(): the current time.
+		&object.Builtin{Name: "now", Fn: func(args ...object.Object) object.Object {
+			if err := checkArgs("time.now", args, 0); err != nil {
+				return err
+			}
+			return object.NewInteger(clock.Now().UnixMilli())
+		}},
+		// sleep(ms): waits for the duration.
+		&object.Builtin{Name: "sleep", Fn: func(args ...object.Object) object.Object {
+			if err := checkArgs("time.sleep", args, 1, object.INTEGER_OBJ); err != nil {
+				return err
+			}
+
+			ms := args[0].(*object.Integer).Value
+			if ms < 0 {
+				return newError(NEGATIVE_DURATION, ms)
+			}
+			if ms > math.MaxInt64/int64(time.Millisecond) {
+				return newError(DURATION_TOO_LONG, ms)
+			}
+			clock.Sleep(time.Duration(ms) * time.Millisecond)
+			return NULL
+		}},
+		&object.Builtin{Name: "format", Fn: timeFormat},
+		&object.Builtin{Name: "parse", Fn: timeParse},
+		&object.Builtin{Name: "duration", Fn: timeDuration},
+	)
+
+	for name, unit := range TIME_UNITS {
+		module.Env.Set(name, object.NewInteger(unit.Milliseconds()))
+		module.Export(name)
+	}
+
+	return module
+}
+
+// format(t, layout?): the time as the layout writes it, in the notation of Go's time package, RFC 3339 by default.
+func timeFormat(args ...object.Object) object.Object {
+	if err := checkArgs("time.format", args, 1, object.INTEGER_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	layout := time.RFC3339
+	if len(args) == 2 {
+		layout = args[1].(*object.String).Value
+	}
+
+	return &object.String{Value: time.UnixMilli(args[0].(*object.Integer).Value).UTC().Format(layout)}
+}
+
+// parse(s, layout?): the time the string, written as the layout, RFC 3339 by default, stands for.
+func timeParse(args ...object.Object) object.Object {
+	if err := checkArgs("time.parse", args, 1, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	layout := time.RFC3339
+	if len(args) == 2 {
+		layout = args[1].(*object.String).Value
+	}
+
+	s := args[0].(*object.String).Value
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return newError(INVALID_TIME, s, layout)
+	}
+
+	return object.NewInteger(t.UnixMilli())
+}
+
+// duration(s): the duration a string such as "1h30m" or "250ms" stands for.
+func timeDuration(args ...object.Object) object.Object {
+	if err := checkArgs("time.duration", args, 1, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	s := args[0].(*object.String).Value
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return newError(INVALID_DURATION, s)
+	}
+
+	return object.NewInteger(d.Milliseconds())
+}
@@ -1,26 +1,37 @@
 package lexer
 
 import (
+	"strings"
+
 	"github.com/MohamTahaB/interpreter-go/token"
 )
 
 type Lexer struct {
-	input        string // The code being lexed.
-	position     int    // Current pos in input, points to curr char
-	readPosition int    // Current reading pos, points to next char
-	ch           byte   // Current char
+	input        string          // The code being lexed.
+	position     int             // Current pos in input, points to curr char
+	readPosition int             // Current reading pos, points to next char
+	ch           byte            // Current char
+	line         int             // Line of the current char
+	column       int             // Column of the current char
+	prev         token.TokenType // Type of the last token read, which tells a regex literal from a division
 }
 
 // Lexer attributes are more or less self explanatory. The reason why we have two pointers: position and readPosition, is that we will need to peek further into the input to see what comes up next
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 	return l
 }
 
 // Helper function to update the position of the considered char in the Lexer instance.
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+	l.column++
+
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
@@ -39,13 +50,34 @@ func (l *Lexer) peekChar() byte {
 	return l.input[l.readPosition]
 }
 
-// Returns the next token the Lexer instance points to.
+// Returns the next token the Lexer instance points to, along with its position.
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
-
 	l.skipWhiteSpace()
 
+	line, column := l.line, l.column
+	tok := l.readToken()
+	tok.Line, tok.Column = line, column
+	l.prev = tok.Type
+
+	return tok
+}
+
+// Reads the token starting at the current char.
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
+
 	switch {
+	case l.ch == '/' && l.regexAllowed():
+		if tok, ok := l.readRegex(); ok {
+			return tok
+		}
+		tok = token.NewToken(token.SLASH, []byte{l.ch})
+
+	case l.ch == '.' && strings.HasPrefix(l.input[l.readPosition:], ".."):
+		l.readChar()
+		l.readChar()
+		tok = token.NewToken(token.ELLIPSIS, []byte(token.ELLIPSIS))
+
 	case token.LegalOneCharLiteral(l.ch):
 
 		var tokType token.TokenType
@@ -57,6 +89,8 @@ func (l *Lexer) NextToken() token.Token {
 			tokType = token.NEQ
 		case l.ch == '=' && l.peekChar() == '=':
 			tokType = token.EQ
+		case l.ch == '=' && l.peekChar() == '>':
+			tokType = token.ARROW
 		case l.ch == '<' && l.peekChar() == '=':
 			tokType = token.LEQ
 		case l.ch == '>' && l.peekChar() == '=':
@@ -133,6 +167,41 @@ func (l *Lexer) readString() string {
 	return l.input[position:l.position]
 }
 
+// Whether a slash can start a regex literal: it cannot right after an operand, where it is a division.
+func (l *Lexer) regexAllowed() bool {
+	switch l.prev {
+	case token.IDENT, token.INT, token.STRING, token.REGEX, token.TRUE, token.FALSE, token.RPARENTHESIS, token.RBRACKET, token.RBRACE:
+		return false
+	}
+
+	return true
+}
+
+// Reads a regex literal, /pattern/flags, whose literal is the whole of it. A backslash escapes the char after it, slashes included.
+// A regex must end on the line it starts on, otherwise nothing is read and the slash is left to be a division.
+func (l *Lexer) readRegex() (token.Token, bool) {
+	start := *l
+	for {
+		l.readChar()
+
+		switch l.ch {
+		case '\\':
+			l.readChar()
+		case '/':
+			l.readChar()
+			for isLetter(l.ch) {
+				l.readChar()
+			}
+			return token.Token{Type: token.REGEX, Literal: l.input[start.position:l.position]}, true
+		}
+
+		if l.ch == 0 || l.ch == '\n' {
+			*l = start
+			return token.Token{}, false
+		}
+	}
+}
+
 func (l *Lexer) skipWhiteSpace() {
 	for l.ch == ' ' || l.ch == '\n' || l.ch == '\t' || l.ch == '\r' {
 		l.readChar()
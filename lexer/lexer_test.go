@@ -75,6 +75,13 @@ func TestNextToken_OK(t *testing.T) {
 
   "foobar"
   "foo bar"
+  "a" in "abc"
+  struct P { x }
+  P{x: 1}.x
+  match (a[0]) { _ => 1 }
+  [...r]
+  const c
+  import "m" as m export
 	`
 
 	l := New(input)
@@ -179,6 +186,45 @@ func TestNextToken_OK(t *testing.T) {
 		{token.SEMICOLON, ";"},
 		{token.STRING, "foobar"},
 		{token.STRING, "foo bar"},
+		{token.STRING, "a"},
+		{token.IN, "in"},
+		{token.STRING, "abc"},
+		{token.STRUCT, "struct"},
+		{token.IDENT, "P"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "x"},
+		{token.RBRACE, "}"},
+		{token.IDENT, "P"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "x"},
+		{token.COLON, ":"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.DOT, "."},
+		{token.IDENT, "x"},
+		{token.MATCH, "match"},
+		{token.LPARENTHESIS, "("},
+		{token.IDENT, "a"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.RPARENTHESIS, ")"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "_"},
+		{token.ARROW, "=>"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.LBRACKET, "["},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "r"},
+		{token.RBRACKET, "]"},
+		{token.CONST, "const"},
+		{token.IDENT, "c"},
+		{token.IMPORT, "import"},
+		{token.STRING, "m"},
+		{token.AS, "as"},
+		{token.IDENT, "m"},
+		{token.EXPORT, "export"},
 		{token.EOF, "\x00"},
 	}
 
@@ -196,3 +242,108 @@ func TestNextToken_OK(t *testing.T) {
 	}
 
 }
+
+// Test the position of tokens.
+func TestNextToken_Positions(t *testing.T) {
+	input := `let five = 5;
+	add(five,
+  "a b" ) != 3`
+
+	tests := []struct {
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{"let", 1, 1},
+		{"five", 1, 5},
+		{"=", 1, 10},
+		{"5", 1, 12},
+		{";", 1, 13},
+		{"add", 2, 2},
+		{"(", 2, 5},
+		{"five", 2, 6},
+		{",", 2, 10},
+		{"a b", 3, 3},
+		{")", 3, 9},
+		{"!=", 3, 11},
+		{"3", 3, 14},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("test[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Fatalf("test[%d] - position wrong. expected=%d:%d, got=%d:%d", i, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+	}
+}
+
+// Test regex literals, told from divisions by the token before them.
+func TestNextToken_Regex(t *testing.T) {
+	input := `let r = /a\/b+/im; x / y / 2; (r) / [2] / 3; f(/=/, "s"); !-/*5;
+	5 /= /x`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "r"},
+		{token.ASSIGN, "="},
+		{token.REGEX, `/a\/b+/im`},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.SLASH, "/"},
+		{token.IDENT, "y"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.LPARENTHESIS, "("},
+		{token.IDENT, "r"},
+		{token.RPARENTHESIS, ")"},
+		{token.SLASH, "/"},
+		{token.LBRACKET, "["},
+		{token.INT, "2"},
+		{token.RBRACKET, "]"},
+		{token.SLASH, "/"},
+		{token.INT, "3"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "f"},
+		{token.LPARENTHESIS, "("},
+		{token.REGEX, "/=/"},
+		{token.COMMA, ","},
+		{token.STRING, "s"},
+		{token.RPARENTHESIS, ")"},
+		{token.SEMICOLON, ";"},
+		{token.NEG, "!"},
+		{token.MINUS, "-"},
+		{token.SLASH, "/"},
+		{token.TIMES, "*"},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.INT, "5"},
+		{token.SLASHEQ, "/="},
+		{token.SLASH, "/"},
+		{token.IDENT, "x"},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("test[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("test[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
@@ -1,20 +1,60 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"os/user"
+	"path/filepath"
 
 	"github.com/MohamTahaB/interpreter-go/repl"
 )
 
 func main() {
+	engine := flag.String("engine", repl.ENGINE_EVAL, "engine running the programs: eval (tree-walking) or vm (bytecode)")
+	optimize := flag.Bool("optimize", false, "fold constants and prune dead branches before running")
+	modulePath := flag.String("path", "", "directories searched for imported modules, separated as in $PATH")
+	root := flag.String("root", "", "directory programs can read and write through the fs module, none by default")
+	seed := flag.Int64("seed", 0, "seed of the random module, for reproducible runs, drawn from the clock if not given")
+	flag.Parse()
+
+	if *engine != repl.ENGINE_EVAL && *engine != repl.ENGINE_VM {
+		fmt.Fprintf(os.Stderr, "unknown engine %q, expected %q or %q\n", *engine, repl.ENGINE_EVAL, repl.ENGINE_VM)
+		os.Exit(2)
+	}
+
+	cfg := repl.Config{Engine: *engine, Optimize: *optimize, SearchPath: filepath.SplitList(*modulePath), Root: *root}
+
+	// Any value of -seed is a seed, zero included, so only its absence means the clock.
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			cfg.Seed = seed
+		}
+	})
+
+	// Given a script file, it is run instead of starting the console.
+	if flag.NArg() > 0 {
+		source, err := os.ReadFile(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		if !repl.Run(flag.Arg(0), string(source), os.Stdout, os.Stderr, cfg) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
 	}
 
 	fmt.Printf("Hello %s! WELCOME TO THE MNKY CONSOLE !!!\n", user.Username)
+	if *engine == repl.ENGINE_VM {
+		fmt.Println("The vm engine runs a subset of the language: no arrays, hashes, structs, classes, exceptions nor modules.")
+	}
 
-	repl.Start(os.Stdin, os.Stdout)
+	repl.Start(os.Stdin, os.Stdout, cfg)
 }
@@ -0,0 +1,141 @@
+package object
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/MohamTahaB/interpreter-go/token"
+)
+
+const BIG_INTEGER_OBJ = "BIG_INTEGER"
+
+// Integer too large for an Integer, which integer operations promote their result to when it overflows.
+// A BigInteger never holds a value an Integer can hold, see NewBigInteger.
+type BigInteger struct {
+	Value *big.Int
+}
+
+// Returns the integer object holding value: an Integer if it fits in 64 bits, a BigInteger otherwise.
+func NewBigInteger(value *big.Int) Object {
+	if value.IsInt64() {
+		return NewInteger(value.Int64())
+	}
+
+	return &BigInteger{Value: value}
+}
+
+func (bi *BigInteger) Type() ObjectType {
+	return BIG_INTEGER_OBJ
+}
+
+func (bi *BigInteger) Inspect() string {
+	return bi.Value.String()
+}
+
+// Never zero, which an Integer holds.
+func (bi *BigInteger) Truthy() bool {
+	return true
+}
+
+func (bi *BigInteger) HashKey() HashKey {
+	return HashKey{Type: bi.Type(), Value: bi.Value.String()}
+}
+
+// Returns the value of an Integer or a BigInteger as a new big.Int, which the caller may modify.
+func ToBigInt(obj Object) (*big.Int, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return big.NewInt(obj.Value), true
+	case *BigInteger:
+		return new(big.Int).Set(obj.Value), true
+	}
+
+	return nil, false
+}
+
+// Big integers mix with integers in arithmetic and comparisons.
+func init() {
+	arithmetic := map[string]func(z, x, y *big.Int) Object{
+		token.PLUS:  func(z, x, y *big.Int) Object { return NewBigInteger(z.Add(x, y)) },
+		token.MINUS: func(z, x, y *big.Int) Object { return NewBigInteger(z.Sub(x, y)) },
+		token.TIMES: func(z, x, y *big.Int) Object { return NewBigInteger(z.Mul(x, y)) },
+		token.SLASH: func(z, x, y *big.Int) Object {
+			if y.Sign() == 0 {
+				return NewError(DIVISION_BY_ZERO)
+			}
+			return NewBigInteger(z.Quo(x, y))
+		},
+	}
+
+	comparisons := map[string]func(cmp int) bool{
+		token.EQ:  func(cmp int) bool { return cmp == 0 },
+		token.NEQ: func(cmp int) bool { return cmp != 0 },
+		token.LT:  func(cmp int) bool { return cmp < 0 },
+		token.LEQ: func(cmp int) bool { return cmp <= 0 },
+		token.GT:  func(cmp int) bool { return cmp > 0 },
+		token.GEQ: func(cmp int) bool { return cmp >= 0 },
+	}
+
+	operands := [][2]ObjectType{
+		{BIG_INTEGER_OBJ, BIG_INTEGER_OBJ},
+		{BIG_INTEGER_OBJ, INTEGER_OBJ},
+		{INTEGER_OBJ, BIG_INTEGER_OBJ},
+	}
+
+	for _, types := range operands {
+		for operator, fn := range arithmetic {
+			fn := fn
+			RegisterInfix(operator, types[0], types[1], func(a, b Object) Object {
+				x, _ := ToBigInt(a)
+				y, _ := ToBigInt(b)
+				return fn(new(big.Int), x, y)
+			})
+		}
+
+		for operator, holds := range comparisons {
+			holds := holds
+			RegisterInfix(operator, types[0], types[1], func(a, b Object) Object {
+				x, _ := ToBigInt(a)
+				y, _ := ToBigInt(b)
+				return NewBoolean(holds(x.Cmp(y)))
+			})
+		}
+	}
+
+	RegisterPrefix(token.MINUS, BIG_INTEGER_OBJ, func(a Object) Object {
+		x, _ := ToBigInt(a)
+		return NewBigInteger(x.Neg(x))
+	})
+	RegisterPrefix(token.NEG, BIG_INTEGER_OBJ, func(a Object) Object {
+		x, _ := ToBigInt(a)
+		return NewBoolean(x.Sign() == 0)
+	})
+}
+
+// Overflow checks of the Integer operations, whose results are promoted to a BigInteger when they do not fit in 64 bits.
+
+func addOverflows(x, y int64) bool {
+	sum := x + y
+	return (sum > x) != (y > 0)
+}
+
+func subOverflows(x, y int64) bool {
+	diff := x - y
+	return (diff < x) != (y > 0)
+}
+
+func mulOverflows(x, y int64) bool {
+	if x == 0 || y == 0 {
+		return false
+	}
+	if x == -1 || y == -1 {
+		return x == math.MinInt64 || y == math.MinInt64
+	}
+
+	return (x*y)/y != x
+}
+
+// Applies the big.Int operation to the values of two Integers.
+func bigResult(op func(z, x, y *big.Int) *big.Int, x, y int64) Object {
+	return NewBigInteger(op(new(big.Int), big.NewInt(x), big.NewInt(y)))
+}
@@ -0,0 +1,34 @@
+package object
+
+const BUILTIN_OBJ = "BUILTIN"
+
+type BuiltinFunction func(args ...Object) Object
+
+// Function implemented by the interpreter itself.
+type Builtin struct {
+	Name string
+	Fn   BuiltinFunction
+}
+
+func (b *Builtin) Type() ObjectType {
+	return BUILTIN_OBJ
+}
+
+func (b *Builtin) Inspect() string {
+	return "builtin " + b.Name
+}
+
+func (b *Builtin) Truthy() bool {
+	return true
+}
+
+// Returns a module exporting each of the builtins under its name, as a module read from a file would.
+func NewBuiltinModule(name string, builtins ...*Builtin) *Module {
+	module := NewModule(name, "", nil)
+	for _, builtin := range builtins {
+		module.Env.Set(builtin.Name, builtin)
+		module.Export(builtin.Name)
+	}
+
+	return module
+}
@@ -0,0 +1,152 @@
+package object
+
+import (
+	"sort"
+	"strings"
+)
+
+// Names methods are bound to the instance and its superclass under.
+const (
+	SELF_NAME  = "self"
+	SUPER_NAME = "super"
+)
+
+// Class, as declared by a class statement.
+type Class struct {
+	Name       string
+	Superclass *Class
+	Methods    map[string]*Function
+}
+
+// Instance of a class. Its fields are set freely, usually by the constructor.
+type Instance struct {
+	Class  *Class
+	Fields map[string]Object
+
+	// Set while the instance is being inspected, so that an instance holding itself is not inspected again.
+	inspecting bool
+}
+
+// Superclass of the class defining a method, as seen from that method through super.
+type Super struct {
+	Instance *Instance
+	Class    *Class
+}
+
+func (c *Class) Type() ObjectType {
+	return CLASS_OBJ
+}
+
+func (c *Class) Inspect() string {
+	return "class " + c.Name
+}
+
+func (c *Class) Truthy() bool {
+	return true
+}
+
+// Returns the method, defined by the class or inherited, and the class defining it.
+func (c *Class) FindMethod(name string) (*Function, *Class, bool) {
+	for class := c; class != nil; class = class.Superclass {
+		if method, ok := class.Methods[name]; ok {
+			return method, class, true
+		}
+	}
+
+	return nil, nil, false
+}
+
+func (i *Instance) Type() ObjectType {
+	return INSTANCE_OBJ
+}
+
+// Fields are printed sorted by name, after the class name. An instance found again within its own fields is printed as Name{...}.
+func (i *Instance) Inspect() string {
+	if i.inspecting {
+		return i.Class.Name + "{...}"
+	}
+	i.inspecting = true
+	defer func() { i.inspecting = false }()
+
+	names := make([]string, 0, len(i.Fields))
+	for name := range i.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := []string{}
+	for _, name := range names {
+		fields = append(fields, name+": "+i.Fields[name].Inspect())
+	}
+
+	return i.Class.Name + "{" + strings.Join(fields, ", ") + "}"
+}
+
+func (i *Instance) Truthy() bool {
+	return true
+}
+
+// Fields shadow methods of the same name.
+func (i *Instance) GetMember(name string) (Object, bool) {
+	if value, ok := i.Fields[name]; ok {
+		return value, true
+	}
+
+	return i.GetMethod(name)
+}
+
+func (i *Instance) SetMember(name string, value Object) bool {
+	i.Fields[name] = value
+	return true
+}
+
+func (i *Instance) GetMethod(name string) (Object, bool) {
+	method, owner, ok := i.Class.FindMethod(name)
+	if !ok {
+		return nil, false
+	}
+
+	return i.bind(method, owner), true
+}
+
+// Returns the method with self bound to the instance and super to the superclass of the class defining it, so that super calls go up the hierarchy one class at a time.
+func (i *Instance) bind(method *Function, owner *Class) *Function {
+	env := NewEnclosedEnvironment(method.Env)
+	env.Set(SELF_NAME, i)
+
+	if owner.Superclass != nil {
+		env.Set(SUPER_NAME, &Super{Instance: i, Class: owner.Superclass})
+	} else {
+		env.Set(SUPER_NAME, NULL)
+	}
+
+	return &Function{
+		Name:       method.Name,
+		Parameters: method.Parameters,
+		Body:       method.Body,
+		Env:        env,
+		FrameSize:  method.FrameSize,
+	}
+}
+
+func (s *Super) Type() ObjectType {
+	return SUPER_OBJ
+}
+
+func (s *Super) Inspect() string {
+	return "super " + s.Class.Name
+}
+
+func (s *Super) Truthy() bool {
+	return true
+}
+
+// Only methods are reached through super, fields belonging to the instance.
+func (s *Super) GetMember(name string) (Object, bool) {
+	method, owner, ok := s.Class.FindMethod(name)
+	if !ok {
+		return nil, false
+	}
+
+	return s.Instance.bind(method, owner), true
+}
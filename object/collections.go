@@ -0,0 +1,108 @@
+package object
+
+import (
+	"strconv"
+	"strings"
+)
+
+type Array struct {
+	Elements []Object
+}
+
+// Key a hashable object is stored under in a hash: equal objects have equal keys.
+type HashKey struct {
+	Type  ObjectType
+	Value string
+}
+
+// Objects usable as hash keys.
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash, whose pairs are kept in insertion order.
+type Hash struct {
+	Pairs map[HashKey]HashPair
+	keys  []HashKey
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: make(map[HashKey]HashPair)}
+}
+
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: strconv.FormatInt(i.Value, 10)}
+}
+
+func (b *Boolean) HashKey() HashKey {
+	return HashKey{Type: b.Type(), Value: strconv.FormatBool(b.Value)}
+}
+
+func (s *String) HashKey() HashKey {
+	return HashKey{Type: s.Type(), Value: s.Value}
+}
+
+func (a *Array) Type() ObjectType {
+	return ARRAY_OBJ
+}
+
+func (a *Array) Inspect() string {
+	elements := []string{}
+	for _, element := range a.Elements {
+		elements = append(elements, element.Inspect())
+	}
+
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+func (a *Array) Truthy() bool {
+	return len(a.Elements) != 0
+}
+
+// Sets the value of the key, a new key going after the others.
+func (h *Hash) Set(key Hashable, value Object) {
+	hashKey := key.HashKey()
+	if _, ok := h.Pairs[hashKey]; !ok {
+		h.keys = append(h.keys, hashKey)
+	}
+
+	h.Pairs[hashKey] = HashPair{Key: key, Value: value}
+}
+
+func (h *Hash) Get(key Hashable) (Object, bool) {
+	pair, ok := h.Pairs[key.HashKey()]
+	return pair.Value, ok
+}
+
+// Returns the pairs of the hash, in insertion order.
+func (h *Hash) Entries() []HashPair {
+	entries := make([]HashPair, 0, len(h.keys))
+	for _, key := range h.keys {
+		entries = append(entries, h.Pairs[key])
+	}
+
+	return entries
+}
+
+func (h *Hash) Type() ObjectType {
+	return HASH_OBJ
+}
+
+func (h *Hash) Inspect() string {
+	pairs := []string{}
+	for _, pair := range h.Entries() {
+		pairs = append(pairs, pair.Key.Inspect()+": "+pair.Value.Inspect())
+	}
+
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+func (h *Hash) Truthy() bool {
+	return len(h.Pairs) != 0
+}
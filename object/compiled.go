@@ -0,0 +1,54 @@
+package object
+
+import (
+	"fmt"
+
+	"github.com/MohamTahaB/interpreter-go/ast"
+)
+
+// Compiled function, as emitted by the compiler into the constant pool.
+type CompiledFunction struct {
+	Instructions  []byte
+	NumLocals     int
+	NumParameters int
+
+	// Names of the locals, by slot, used to report unbound identifiers.
+	LocalNames []string
+
+	// Kept around for Inspect only.
+	Name       string
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+}
+
+// Closure wraps a compiled function together with the free variables it captured.
+// This is the function value the virtual machine hands around at runtime.
+type Closure struct {
+	Fn   *CompiledFunction
+	Free []Object
+}
+
+func (cf *CompiledFunction) Type() ObjectType {
+	return COMPILED_FUNCTION_OBJ
+}
+
+func (cf *CompiledFunction) Inspect() string {
+	return fmt.Sprintf("CompiledFunction[%p]", cf)
+}
+
+func (cf *CompiledFunction) Truthy() bool {
+	return true
+}
+
+// A closure is a function as far as the language is concerned, so it reports the same type as the evaluator's functions.
+func (c *Closure) Type() ObjectType {
+	return FUNCTION_OBJ
+}
+
+func (c *Closure) Inspect() string {
+	return inspectFunction(c.Fn.Name, c.Fn.Parameters, c.Fn.Body)
+}
+
+func (c *Closure) Truthy() bool {
+	return true
+}
@@ -0,0 +1,81 @@
+package object
+
+// Types of the errors raised by the interpreter itself.
+const (
+	ERROR_KIND          = "Error"
+	TYPE_ERROR          = "TypeError"
+	VALUE_ERROR         = "ValueError"
+	NAME_ERROR          = "NameError"
+	ATTRIBUTE_ERROR     = "AttributeError"
+	KEY_ERROR           = "KeyError"
+	ARGUMENT_ERROR      = "ArgumentError"
+	ZERO_DIVISION_ERROR = "ZeroDivisionError"
+	IMPORT_ERROR        = "ImportError"
+	IO_ERROR            = "IOError"
+)
+
+// Type of the errors raised with each message of the package.
+var ERROR_KINDS = map[string]string{
+	UNKNOWN_OP_PREFIX_MSG:   TYPE_ERROR,
+	UNKNOWN_OP_INFIX_MSG:    TYPE_ERROR,
+	TYPE_MISMATCH_INFIX_MSG: TYPE_ERROR,
+	DIVISION_BY_ZERO:        ZERO_DIVISION_ERROR,
+	NEGATIVE_REPEAT_COUNT:   VALUE_ERROR,
+	REPEAT_TOO_LONG:         VALUE_ERROR,
+}
+
+// Error caught by a catch clause, as a value: unlike an Error, it does not unwind the evaluation.
+type Exception struct {
+	Error *Error
+}
+
+// Returns the error raised by throwing the value. Instances of a class are errors of that type, with their message field as message.
+func NewThrownError(value Object) *Error {
+	err := &Error{Message: value.Inspect(), Kind: ERROR_KIND, Value: value}
+
+	if instance, ok := value.(*Instance); ok {
+		err.Kind = instance.Class.Name
+		if message, ok := instance.Fields["message"]; ok {
+			err.Message = message.Inspect()
+		}
+	}
+
+	return err
+}
+
+func (e *Error) KindName() string {
+	if e.Kind == "" {
+		return ERROR_KIND
+	}
+
+	return e.Kind
+}
+
+func (ex *Exception) Type() ObjectType {
+	return EXCEPTION_OBJ
+}
+
+func (ex *Exception) Inspect() string {
+	return ex.Error.KindName() + ": " + ex.Error.Message
+}
+
+func (ex *Exception) Truthy() bool {
+	return true
+}
+
+// The message and type of the error, and the value thrown, null for errors raised by the interpreter.
+func (ex *Exception) GetMember(name string) (Object, bool) {
+	switch name {
+	case "message":
+		return &String{Value: ex.Error.Message}, true
+	case "type":
+		return &String{Value: ex.Error.KindName()}, true
+	case "value":
+		if ex.Error.Value == nil {
+			return NULL, true
+		}
+		return ex.Error.Value, true
+	}
+
+	return nil, false
+}
@@ -1,17 +1,14 @@
 package object
 
-import "strings"
+import (
+	"math"
+	"math/big"
+	"strings"
+)
 
 type InfixFunc[T any] func(a, b T) T
 
-var OBJECT_INFIX_PLUS_FUNCS map[ObjectType]InfixFunc[Object] = map[ObjectType]InfixFunc[Object]{
-	INTEGER_OBJ: infixPlusInteger,
-	STRING_OBJ:  infixPlusString,
-}
-
-var OBJECT_INFIX_MINUS_FUNCS map[ObjectType]InfixFunc[Object] = map[ObjectType]InfixFunc[Object]{
-	INTEGER_OBJ: infixMinusInteger,
-}
+type PrefixFunc[T any] func(a T) T
 
 // Define Infix Functions
 
@@ -22,9 +19,11 @@ func infixPlusInteger(a, b Object) Object {
 	IntA := a.(*Integer)
 	IntB := b.(*Integer)
 
-	return &Integer{
-		Value: IntA.Value + IntB.Value,
+	if addOverflows(IntA.Value, IntB.Value) {
+		return bigResult((*big.Int).Add, IntA.Value, IntB.Value)
 	}
+
+	return NewInteger(IntA.Value + IntB.Value)
 }
 
 func infixPlusString(a, b Object) Object {
@@ -49,7 +48,122 @@ func infixMinusInteger(a, b Object) Object {
 	IntA := a.(*Integer)
 	IntB := b.(*Integer)
 
-	return &Integer{
-		Value: IntA.Value - IntB.Value,
+	if subOverflows(IntA.Value, IntB.Value) {
+		return bigResult((*big.Int).Sub, IntA.Value, IntB.Value)
+	}
+
+	return NewInteger(IntA.Value - IntB.Value)
+}
+
+// Times
+
+func infixTimesInteger(a, b Object) Object {
+	IntA := a.(*Integer)
+	IntB := b.(*Integer)
+
+	if mulOverflows(IntA.Value, IntB.Value) {
+		return bigResult((*big.Int).Mul, IntA.Value, IntB.Value)
+	}
+
+	return NewInteger(IntA.Value * IntB.Value)
+}
+
+// Repeats a string a number of times, the count on either side.
+func infixTimesString(a, b Object) Object {
+	if a.Type() == INTEGER_OBJ {
+		a, b = b, a
+	}
+
+	return RepeatString(a.(*String).Value, b.(*Integer).Value)
+}
+
+// Returns the string repeated count times, or an error if the count is negative or the result longer than MAX_REPEAT_LENGTH.
+func RepeatString(s string, count int64) Object {
+	if count < 0 {
+		return NewError(NEGATIVE_REPEAT_COUNT, count)
+	}
+	if count > 0 && int64(len(s)) > MAX_REPEAT_LENGTH/count {
+		return NewError(REPEAT_TOO_LONG, len(s), count)
+	}
+
+	return &String{Value: strings.Repeat(s, int(count))}
+}
+
+// Slash
+
+func infixSlashInteger(a, b Object) Object {
+	IntA := a.(*Integer)
+	IntB := b.(*Integer)
+
+	if IntB.Value == 0 {
+		return NewError(DIVISION_BY_ZERO)
+	}
+
+	// The only quotient that overflows.
+	if IntA.Value == math.MinInt64 && IntB.Value == -1 {
+		return bigResult((*big.Int).Quo, IntA.Value, IntB.Value)
 	}
+
+	return NewInteger(IntA.Value / IntB.Value)
+}
+
+// Comparisons
+
+func compareIntegers(cmp func(a, b int64) bool) InfixFunc[Object] {
+	return func(a, b Object) Object {
+		return NewBoolean(cmp(a.(*Integer).Value, b.(*Integer).Value))
+	}
+}
+
+func compareBooleans(cmp func(a, b bool) bool) InfixFunc[Object] {
+	return func(a, b Object) Object {
+		return NewBoolean(cmp(a.(*Boolean).Value, b.(*Boolean).Value))
+	}
+}
+
+// Strings compare lexicographically, byte by byte.
+func compareStrings(cmp func(a, b string) bool) InfixFunc[Object] {
+	return func(a, b Object) Object {
+		return NewBoolean(cmp(a.(*String).Value, b.(*String).Value))
+	}
+}
+
+// Null is only ever equal to itself, and can be compared with any object.
+
+func infixEQNull(a, b Object) Object {
+	return NewBoolean(a == b)
+}
+
+func infixNEQNull(a, b Object) Object {
+	return NewBoolean(a != b)
+}
+
+// In
+
+// Whether a is a substring of b.
+func infixInString(a, b Object) Object {
+	return NewBoolean(strings.Contains(b.(*String).Value, a.(*String).Value))
+}
+
+// Define Prefix Functions
+
+func prefixMinusInteger(a Object) Object {
+	if a.(*Integer).Value == math.MinInt64 {
+		return NewBigInteger(new(big.Int).Neg(big.NewInt(math.MinInt64)))
+	}
+
+	return NewInteger(-a.(*Integer).Value)
+}
+
+func prefixNegationBoolean(a Object) Object {
+	return NewBoolean(!a.(*Boolean).Value)
+}
+
+func prefixNegationInteger(a Object) Object {
+	return NewBoolean(a.(*Integer).Value == 0)
+}
+
+// Negates any other value to true, as only booleans and integers have a native truth value for the operator.
+func prefixNegation(a Object) Object {
+	return TRUE
 }
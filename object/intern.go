@@ -0,0 +1,44 @@
+package object
+
+// Shared instances, so that evaluating a literal or an operation does not allocate for the most common values.
+
+const (
+	SMALL_INTEGERS_MIN = -128
+	SMALL_INTEGERS_MAX = 1024
+)
+
+var (
+	NULL  = &Null{}
+	TRUE  = &Boolean{Value: true}
+	FALSE = &Boolean{Value: false}
+
+	smallIntegers = newSmallIntegers()
+)
+
+func newSmallIntegers() []*Integer {
+	out := make([]*Integer, SMALL_INTEGERS_MAX-SMALL_INTEGERS_MIN+1)
+	for idx := range out {
+		out[idx] = &Integer{Value: int64(idx + SMALL_INTEGERS_MIN)}
+	}
+
+	return out
+}
+
+// Returns the Integer holding value, shared if value is small enough.
+// Integers are never mutated, so sharing them is safe.
+func NewInteger(value int64) *Integer {
+	if SMALL_INTEGERS_MIN <= value && value <= SMALL_INTEGERS_MAX {
+		return smallIntegers[value-SMALL_INTEGERS_MIN]
+	}
+
+	return &Integer{Value: value}
+}
+
+// Returns the TRUE or FALSE singleton.
+func NewBoolean(value bool) *Boolean {
+	if value {
+		return TRUE
+	}
+
+	return FALSE
+}
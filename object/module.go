@@ -0,0 +1,61 @@
+package object
+
+import "github.com/MohamTahaB/interpreter-go/token"
+
+const MODULE_OBJ = "MODULE"
+
+// Loads the module an import statement names, resolving its path from the importing module.
+// An error the module raises as it is evaluated records the import, at its token, in its trace.
+type Importer interface {
+	Import(path string, importer *Module, at token.Token) (*Module, *Error)
+}
+
+// Module a program runs as, whose top level bindings live in its environment.
+type Module struct {
+	Name string
+
+	// Absolute path of the file the module was read from, empty if it was not read from a file.
+	Path string
+
+	Env *Environment
+
+	// Loads the modules the module imports.
+	Importer Importer
+
+	exports map[string]bool
+}
+
+// Returns a module with an empty environment, which knows the module it belongs to.
+func NewModule(name, path string, importer Importer) *Module {
+	module := &Module{Name: name, Path: path, Importer: importer, exports: make(map[string]bool)}
+	module.Env = NewEnvironment()
+	module.Env.module = module
+
+	return module
+}
+
+// Exposes the binding of the module's environment as a member.
+func (m *Module) Export(name string) {
+	m.exports[name] = true
+}
+
+func (m *Module) Type() ObjectType {
+	return MODULE_OBJ
+}
+
+func (m *Module) Inspect() string {
+	return "<module " + m.Name + ">"
+}
+
+func (m *Module) Truthy() bool {
+	return true
+}
+
+// Exported bindings are read from the module's environment, so that they reflect any later assignment.
+func (m *Module) GetMember(name string) (Object, bool) {
+	if !m.exports[name] {
+		return nil, false
+	}
+
+	return m.Env.Get(name)
+}
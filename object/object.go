@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/MohamTahaB/interpreter-go/ast"
+	"github.com/MohamTahaB/interpreter-go/token"
 )
 
 type ObjectType string
@@ -14,11 +15,26 @@ const (
 	BOOLEAN_OBJ = "BOOLEAN"
 	NULL_OBJ    = "NULL"
 
-	RETURN_OBJ   = "RETURN_VAL"
-	ERROR_OBJ    = "ERROR"
-	FUNCTION_OBJ = "FUNCTION"
+	RETURN_OBJ    = "RETURN_VAL"
+	TAIL_CALL_OBJ = "TAIL_CALL"
+	ERROR_OBJ     = "ERROR"
+	FUNCTION_OBJ  = "FUNCTION"
+
+	COMPILED_FUNCTION_OBJ = "COMPILED_FUNCTION"
 
 	STRING_OBJ = "STRING"
+
+	STRUCT_OBJ          = "STRUCT"
+	STRUCT_INSTANCE_OBJ = "STRUCT_INSTANCE"
+
+	EXCEPTION_OBJ = "EXCEPTION"
+
+	CLASS_OBJ    = "CLASS"
+	INSTANCE_OBJ = "INSTANCE"
+	SUPER_OBJ    = "SUPER"
+
+	ARRAY_OBJ = "ARRAY"
+	HASH_OBJ  = "HASH"
 )
 
 type Object interface {
@@ -27,6 +43,18 @@ type Object interface {
 	Truthy() bool
 }
 
+// Objects whose members are accessed with the dot operator.
+type Members interface {
+	Object
+	GetMember(name string) (Object, bool)
+}
+
+// Objects whose members can also be assigned. Returns false if the object has no such member.
+type MutableMembers interface {
+	Members
+	SetMember(name string, value Object) bool
+}
+
 // Integer type
 type Integer struct {
 	Value int64
@@ -45,15 +73,42 @@ type ReturnValue struct {
 	Value Object
 }
 
-// Internal Error Wrapper
+// Tail call wrapper, the call being left for the caller to make
+type TailCall struct {
+	Function  Object
+	Arguments []Object
+
+	// Opening parenthesis of the call, for the trace of errors it raises.
+	Call token.Token
+}
+
+// Internal Error Wrapper, unwinding the evaluation until caught
 type Error struct {
 	Message string
+
+	// Type of the error, as seen by catch clauses. Empty for a plain error.
+	Kind string
+
+	// Value given to throw, if the error was thrown.
+	Value Object
+
+	// Calls the error unwound through, innermost first.
+	Trace []Frame
 }
 
 // Environment
+// Globals live in the store, by name. Bindings the resolver located live in slots, by index.
 type Environment struct {
 	store map[string]Object
+	slots []Object
 	outer *Environment
+
+	// Bindings of the environment declared constant, by name and by slot.
+	constants     map[string]bool
+	constantSlots map[int]bool
+
+	// Module whose top level bindings the environment holds, nil for any other environment.
+	module *Module
 }
 
 type String struct {
@@ -62,9 +117,12 @@ type String struct {
 
 // Function Object
 type Function struct {
+	// Name the function was defined with, empty for an anonymous one.
+	Name       string
 	Parameters []*ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
+	FrameSize  int
 }
 
 func (i *Integer) Inspect() string {
@@ -115,6 +173,18 @@ func (rv *ReturnValue) Truthy() bool {
 	return rv.Value.Truthy()
 }
 
+func (tc *TailCall) Inspect() string {
+	return tc.Function.Inspect()
+}
+
+func (tc *TailCall) Type() ObjectType {
+	return TAIL_CALL_OBJ
+}
+
+func (tc *TailCall) Truthy() bool {
+	return true
+}
+
 func (e *Error) Inspect() string {
 	return e.Message
 }
@@ -134,10 +204,9 @@ func NewEnvironment() *Environment {
 	}
 }
 
+// Returns an environment whose bindings are looked up by name before the outer ones. Its store is only allocated once something is bound in it.
 func NewEnclosedEnvironment(outer *Environment) *Environment {
-	env := NewEnvironment()
-	env.outer = outer
-	return env
+	return &Environment{outer: outer}
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
@@ -153,30 +222,103 @@ func (e *Environment) Get(name string) (Object, bool) {
 	return e.outer.Get(name)
 }
 
+// Returns an environment holding size slots, as needed to call a resolved function.
+func NewFrameEnvironment(outer *Environment, size int) *Environment {
+	return &Environment{
+		slots: make([]Object, size),
+		outer: outer,
+	}
+}
+
 func (e *Environment) Set(name string, value Object) Object {
+	if e.store == nil {
+		e.store = make(map[string]Object)
+	}
+
 	e.store[name] = value
 	return value
 }
 
-func (f *Function) Type() ObjectType {
-	return FUNCTION_OBJ
+// Returns the value at the given slot of the environment depth levels up.
+func (e *Environment) GetAt(depth, slot int) (Object, bool) {
+	obj := e.Up(depth).slots[slot]
+	return obj, obj != nil
 }
 
-func (f *Function) Inspect() string {
-	var out strings.Builder
-	params := []string{}
-	for _, p := range f.Parameters {
-		params = append(params, p.String())
+func (e *Environment) SetAt(slot int, value Object) Object {
+	e.slots[slot] = value
+	return value
+}
+
+// Binds the name as a constant of the environment.
+func (e *Environment) SetConstant(name string, value Object) Object {
+	if e.constants == nil {
+		e.constants = make(map[string]bool)
 	}
 
-	out.WriteString("fn")
-	out.WriteString("(")
-	out.WriteString(strings.Join(params, ", "))
-	out.WriteString(") {\n")
-	out.WriteString(f.Body.String())
-	out.WriteString("\n}")
+	e.constants[name] = true
+	return e.Set(name, value)
+}
 
-	return out.String()
+// Same as above, for a slot.
+func (e *Environment) SetConstantAt(slot int, value Object) Object {
+	if e.constantSlots == nil {
+		e.constantSlots = make(map[int]bool)
+	}
+
+	e.constantSlots[slot] = true
+	return e.SetAt(slot, value)
+}
+
+// Whether the name is bound as a constant in this environment, outer ones left out.
+func (e *Environment) IsConstant(name string) bool {
+	return e.constants[name]
+}
+
+func (e *Environment) IsConstantAt(slot int) bool {
+	return e.constantSlots[slot]
+}
+
+// Returns the environment the name is bound in, looking it up by name as Get does.
+func (e *Environment) Owner(name string) (*Environment, bool) {
+	for ; e != nil; e = e.outer {
+		if _, ok := e.store[name]; ok {
+			return e, true
+		}
+	}
+
+	return nil, false
+}
+
+// Returns the module the environment belongs to, the one of its outermost environment, or nil if there is none.
+func (e *Environment) Module() *Module {
+	for e.outer != nil {
+		e = e.outer
+	}
+
+	return e.module
+}
+
+// Whether the environment holds the top level bindings of a module.
+func (e *Environment) IsModule() bool {
+	return e.module != nil
+}
+
+// Returns the environment depth levels up.
+func (e *Environment) Up(depth int) *Environment {
+	for ; depth > 0; depth-- {
+		e = e.outer
+	}
+
+	return e
+}
+
+func (f *Function) Type() ObjectType {
+	return FUNCTION_OBJ
+}
+
+func (f *Function) Inspect() string {
+	return inspectFunction(f.Name, f.Parameters, f.Body)
 }
 
 func (f *Function) Truthy() bool {
@@ -194,3 +336,24 @@ func (s *String) Inspect() string {
 func (s *String) Truthy() bool {
 	return len(s.Value) != 0
 }
+
+// Helper function shared by the evaluated and compiled function objects, so both engines print functions the same way.
+func inspectFunction(name string, parameters []*ast.Identifier, body *ast.BlockStatement) string {
+	var out strings.Builder
+	params := []string{}
+	for _, p := range parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString("fn")
+	if name != "" {
+		out.WriteString(" " + name)
+	}
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") {\n")
+	out.WriteString(body.String())
+	out.WriteString("\n}")
+
+	return out.String()
+}
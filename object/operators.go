@@ -0,0 +1,169 @@
+package object
+
+import (
+	"fmt"
+
+	"github.com/MohamTahaB/interpreter-go/token"
+)
+
+const (
+	UNKNOWN_OP_PREFIX_MSG   = "unknown operator: %s%s"
+	UNKNOWN_OP_INFIX_MSG    = "unknown operator: %s %s %s"
+	TYPE_MISMATCH_INFIX_MSG = "type mismatch: %s %s %s"
+	DIVISION_BY_ZERO        = "division by 0"
+	NEGATIVE_REPEAT_COUNT   = "negative repeat count: %d"
+	REPEAT_TOO_LONG         = "repeated string too long: %d bytes %d times"
+)
+
+// Length in bytes past which a string cannot be repeated, so that a large count fails rather than exhausting memory.
+const MAX_REPEAT_LENGTH = 1 << 28
+
+// Wildcard operand type, matching any type no operator is specifically registered for.
+const ANY_OBJ = "ANY"
+
+// Operator and operand types an infix function is registered for.
+type InfixOperands struct {
+	Operator    string
+	Left, Right ObjectType
+}
+
+// Same as above, for a prefix function.
+type PrefixOperands struct {
+	Operator string
+	Right    ObjectType
+}
+
+// Every infix operator of the language goes through this registry. New object types register their own with RegisterInfix.
+var INFIX_OPERATORS = map[InfixOperands]InfixFunc[Object]{
+	{token.PLUS, INTEGER_OBJ, INTEGER_OBJ}: infixPlusInteger,
+	{token.PLUS, STRING_OBJ, STRING_OBJ}:   infixPlusString,
+
+	{token.MINUS, INTEGER_OBJ, INTEGER_OBJ}: infixMinusInteger,
+
+	{token.TIMES, INTEGER_OBJ, INTEGER_OBJ}: infixTimesInteger,
+	{token.TIMES, STRING_OBJ, INTEGER_OBJ}:  infixTimesString,
+	{token.TIMES, INTEGER_OBJ, STRING_OBJ}:  infixTimesString,
+
+	{token.SLASH, INTEGER_OBJ, INTEGER_OBJ}: infixSlashInteger,
+
+	{token.EQ, INTEGER_OBJ, INTEGER_OBJ}: compareIntegers(func(a, b int64) bool { return a == b }),
+	{token.EQ, BOOLEAN_OBJ, BOOLEAN_OBJ}: compareBooleans(func(a, b bool) bool { return a == b }),
+	{token.EQ, STRING_OBJ, STRING_OBJ}:   compareStrings(func(a, b string) bool { return a == b }),
+	{token.EQ, NULL_OBJ, ANY_OBJ}:        infixEQNull,
+	{token.EQ, ANY_OBJ, NULL_OBJ}:        infixEQNull,
+
+	{token.NEQ, INTEGER_OBJ, INTEGER_OBJ}: compareIntegers(func(a, b int64) bool { return a != b }),
+	{token.NEQ, BOOLEAN_OBJ, BOOLEAN_OBJ}: compareBooleans(func(a, b bool) bool { return a != b }),
+	{token.NEQ, STRING_OBJ, STRING_OBJ}:   compareStrings(func(a, b string) bool { return a != b }),
+	{token.NEQ, NULL_OBJ, ANY_OBJ}:        infixNEQNull,
+	{token.NEQ, ANY_OBJ, NULL_OBJ}:        infixNEQNull,
+
+	{token.LT, INTEGER_OBJ, INTEGER_OBJ}: compareIntegers(func(a, b int64) bool { return a < b }),
+	{token.LT, STRING_OBJ, STRING_OBJ}:   compareStrings(func(a, b string) bool { return a < b }),
+
+	{token.LEQ, INTEGER_OBJ, INTEGER_OBJ}: compareIntegers(func(a, b int64) bool { return a <= b }),
+	{token.LEQ, STRING_OBJ, STRING_OBJ}:   compareStrings(func(a, b string) bool { return a <= b }),
+
+	{token.GT, INTEGER_OBJ, INTEGER_OBJ}: compareIntegers(func(a, b int64) bool { return a > b }),
+	{token.GT, STRING_OBJ, STRING_OBJ}:   compareStrings(func(a, b string) bool { return a > b }),
+
+	{token.GEQ, INTEGER_OBJ, INTEGER_OBJ}: compareIntegers(func(a, b int64) bool { return a >= b }),
+	{token.GEQ, STRING_OBJ, STRING_OBJ}:   compareStrings(func(a, b string) bool { return a >= b }),
+
+	{token.IN, STRING_OBJ, STRING_OBJ}: infixInString,
+}
+
+// Every prefix operator of the language goes through this registry. New object types register their own with RegisterPrefix.
+var PREFIX_OPERATORS = map[PrefixOperands]PrefixFunc[Object]{
+	{token.MINUS, INTEGER_OBJ}: prefixMinusInteger,
+
+	{token.NEG, BOOLEAN_OBJ}: prefixNegationBoolean,
+	{token.NEG, INTEGER_OBJ}: prefixNegationInteger,
+	{token.NEG, ANY_OBJ}:     prefixNegation,
+}
+
+// Special method a user defined type implements an infix operator with, called on the left operand, or on the container for in.
+var OPERATOR_METHODS = map[string]string{
+	token.PLUS:  "__add__",
+	token.MINUS: "__sub__",
+	token.TIMES: "__mul__",
+	token.SLASH: "__div__",
+	token.EQ:    "__eq__",
+	token.NEQ:   "__ne__",
+	token.LT:    "__lt__",
+	token.LEQ:   "__le__",
+	token.GT:    "__gt__",
+	token.GEQ:   "__ge__",
+	token.IN:    "__contains__",
+}
+
+// Objects of user defined types, that may overload operators the registry has no entry for by defining their special methods.
+type Overloadable interface {
+	Object
+	GetMethod(name string) (Object, bool)
+}
+
+func RegisterInfix(operator string, left, right ObjectType, fn InfixFunc[Object]) {
+	INFIX_OPERATORS[InfixOperands{operator, left, right}] = fn
+}
+
+func RegisterPrefix(operator string, right ObjectType, fn PrefixFunc[Object]) {
+	PREFIX_OPERATORS[PrefixOperands{operator, right}] = fn
+}
+
+// Returns the infix function registered for the operand types, trying the exact types first, then wildcards.
+func LookupInfix(operator string, left, right ObjectType) (InfixFunc[Object], bool) {
+	for _, operands := range []InfixOperands{
+		{operator, left, right},
+		{operator, left, ANY_OBJ},
+		{operator, ANY_OBJ, right},
+		{operator, ANY_OBJ, ANY_OBJ},
+	} {
+		if fn, ok := INFIX_OPERATORS[operands]; ok {
+			return fn, true
+		}
+	}
+
+	return nil, false
+}
+
+// Same as above, for a prefix function. Null never matches the wildcard, as no operator applies to it.
+func LookupPrefix(operator string, right ObjectType) (PrefixFunc[Object], bool) {
+	if fn, ok := PREFIX_OPERATORS[PrefixOperands{operator, right}]; ok {
+		return fn, true
+	}
+
+	if right == NULL_OBJ {
+		return nil, false
+	}
+
+	fn, ok := PREFIX_OPERATORS[PrefixOperands{operator, ANY_OBJ}]
+	return fn, ok
+}
+
+// Applies the infix operator to the operands. Without a registered function, the operands types mismatch if they differ, and the operator is unknown otherwise.
+func ApplyInfix(operator string, l, r Object) Object {
+	fn, ok := LookupInfix(operator, l.Type(), r.Type())
+	if !ok {
+		if l.Type() != r.Type() {
+			return NewError(TYPE_MISMATCH_INFIX_MSG, l.Type(), operator, r.Type())
+		}
+		return NewError(UNKNOWN_OP_INFIX_MSG, l.Type(), operator, r.Type())
+	}
+
+	return fn(l, r)
+}
+
+// Applies the prefix operator to the operand.
+func ApplyPrefix(operator string, right Object) Object {
+	fn, ok := LookupPrefix(operator, right.Type())
+	if !ok {
+		return NewError(UNKNOWN_OP_PREFIX_MSG, operator, right.Type())
+	}
+
+	return fn(right)
+}
+
+func NewError(format string, a ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, a...), Kind: ERROR_KINDS[format]}
+}
@@ -0,0 +1,59 @@
+package object
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+const REGEX_OBJ = "REGEX"
+
+// Flags a regex can be given: i ignores case, m makes ^ and $ match at line breaks, s lets . match a newline.
+const REGEX_FLAGS = "ims"
+
+// Compiled regular expression, in the syntax of Go's regexp package.
+type Regex struct {
+	Pattern string
+	Flags   string
+	Value   *regexp.Regexp
+}
+
+// Compiles the pattern with the flags. A syntax error tells what is wrong without quoting the pattern back.
+func NewRegex(pattern, flags string) (*Regex, error) {
+	for _, flag := range flags {
+		if !strings.ContainsRune(REGEX_FLAGS, flag) {
+			return nil, fmt.Errorf("unknown flag %c", flag)
+		}
+	}
+
+	source := pattern
+	if flags != "" {
+		source = "(?" + flags + ")" + pattern
+	}
+
+	value, err := regexp.Compile(source)
+	var syntaxErr *syntax.Error
+	if errors.As(err, &syntaxErr) {
+		return nil, errors.New(string(syntaxErr.Code))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Regex{Pattern: pattern, Flags: flags, Value: value}, nil
+}
+
+func (r *Regex) Type() ObjectType {
+	return REGEX_OBJ
+}
+
+// Printed as the literal it can be written as.
+func (r *Regex) Inspect() string {
+	return "/" + r.Pattern + "/" + r.Flags
+}
+
+func (r *Regex) Truthy() bool {
+	return true
+}
@@ -0,0 +1,79 @@
+package object
+
+import "strings"
+
+// Struct type, as declared by a struct statement.
+type Struct struct {
+	Name   string
+	Fields []string
+}
+
+// Instance of a struct, holding a value for each of its fields.
+type StructInstance struct {
+	Struct *Struct
+	Fields map[string]Object
+
+	// Set while the instance is being inspected, so that an instance holding itself is not inspected again.
+	inspecting bool
+}
+
+func (s *Struct) Type() ObjectType {
+	return STRUCT_OBJ
+}
+
+func (s *Struct) Inspect() string {
+	return "struct " + s.Name + " { " + strings.Join(s.Fields, ", ") + " }"
+}
+
+func (s *Struct) Truthy() bool {
+	return true
+}
+
+// Whether the struct declares the field.
+func (s *Struct) HasField(name string) bool {
+	for _, field := range s.Fields {
+		if field == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (si *StructInstance) Type() ObjectType {
+	return STRUCT_INSTANCE_OBJ
+}
+
+// Fields are printed in declaration order. An instance found again within its own fields is printed as Name{...}.
+func (si *StructInstance) Inspect() string {
+	if si.inspecting {
+		return si.Struct.Name + "{...}"
+	}
+	si.inspecting = true
+	defer func() { si.inspecting = false }()
+
+	fields := []string{}
+	for _, field := range si.Struct.Fields {
+		fields = append(fields, field+": "+si.Fields[field].Inspect())
+	}
+
+	return si.Struct.Name + "{" + strings.Join(fields, ", ") + "}"
+}
+
+func (si *StructInstance) Truthy() bool {
+	return true
+}
+
+func (si *StructInstance) GetMember(name string) (Object, bool) {
+	value, ok := si.Fields[name]
+	return value, ok
+}
+
+func (si *StructInstance) SetMember(name string, value Object) bool {
+	if !si.Struct.HasField(name) {
+		return false
+	}
+
+	si.Fields[name] = value
+	return true
+}
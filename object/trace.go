@@ -0,0 +1,52 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MohamTahaB/interpreter-go/token"
+)
+
+// Name given in traces to functions bound to no name.
+const ANONYMOUS_FUNCTION = "<anonymous>"
+
+// Call of a function an error unwound through.
+type Frame struct {
+	Function string
+
+	// Position of the call, zero for calls the interpreter makes itself, such as operator methods.
+	Line, Column int
+}
+
+// Records the error unwinding through a call of the function, made at the position of the token.
+func (e *Error) AddFrame(function string, call token.Token) {
+	if function == "" {
+		function = ANONYMOUS_FUNCTION
+	}
+
+	e.Trace = append(e.Trace, Frame{Function: function, Line: call.Line, Column: call.Column})
+}
+
+// Returns the error as a readable traceback, the outermost call first, and the error itself last.
+func (e *Error) Traceback() string {
+	var out strings.Builder
+
+	if len(e.Trace) != 0 {
+		out.WriteString("Traceback (most recent call last):\n")
+	}
+
+	for idx := len(e.Trace) - 1; idx >= 0; idx-- {
+		out.WriteString("  " + e.Trace[idx].String() + "\n")
+	}
+
+	out.WriteString(e.KindName() + ": " + e.Message)
+	return out.String()
+}
+
+func (f Frame) String() string {
+	if f.Line == 0 {
+		return "in " + f.Function
+	}
+
+	return fmt.Sprintf("in %s, called at line %d, column %d", f.Function, f.Line, f.Column)
+}
@@ -0,0 +1,477 @@
+package optimize
+
+import (
+	"github.com/MohamTahaB/interpreter-go/ast"
+	"github.com/MohamTahaB/interpreter-go/eval"
+	"github.com/MohamTahaB/interpreter-go/object"
+	"github.com/MohamTahaB/interpreter-go/token"
+)
+
+// Literal a let binding was found to hold.
+type constant struct {
+	value ast.Expression
+
+	// Globals can be bound again by a later program sharing the environment (a REPL line), so they are not inlined into functions, which may run after that.
+	global bool
+}
+
+type optimizer struct {
+	// Number of times each name is bound in the program. Only names bound once are inlined.
+	bindings map[string]int
+}
+
+// Rewrites the program in place, and returns it:
+//   - integer, boolean and string prefix and infix expressions on literals are folded, unless evaluating them errors,
+//   - if expressions on a constant condition lose their dead branch,
+//   - let bindings of a literal, bound once in the program, are inlined in the statements following them.
+func Optimize(program *ast.Program) *ast.Program {
+	o := &optimizer{bindings: make(map[string]int)}
+	o.countBindings(program)
+
+	program.Statements = o.statements(program.Statements, map[string]constant{}, true)
+	return program
+}
+
+// Optimizes a list of statements run one after the other, in which constant bindings accumulate.
+func (o *optimizer) statements(statements []ast.Statement, constants map[string]constant, global bool) []ast.Statement {
+	constants = copyConstants(constants)
+	out := []ast.Statement{}
+
+	// Declared functions are bound, and may be called, before any statement of the list runs.
+	declared := copyConstants(constants)
+
+	for idx, statement := range statements {
+		if _, ok := statement.(*ast.FunctionStatement); ok {
+			statement = o.statement(statement, declared, global)
+		} else {
+			statement = o.statement(statement, constants, global)
+		}
+
+		// Dropping a statement that has no effect, unless it gives the value of the list.
+		if idx != len(statements)-1 && isDeadStatement(statement) {
+			continue
+		}
+
+		if let, ok := statement.(*ast.LetStatement); ok && isLiteral(let.Value) && o.bindings[let.Name.Value] == 1 {
+			constants[let.Name.Value] = constant{value: let.Value, global: global}
+		}
+
+		out = append(out, statement)
+	}
+
+	return out
+}
+
+func (o *optimizer) statement(statement ast.Statement, constants map[string]constant, global bool) ast.Statement {
+	switch statement := statement.(type) {
+	case *ast.ExpressionStatement:
+		statement.Expression = o.expression(statement.Expression, constants, global)
+
+	case *ast.LetStatement:
+		statement.Value = o.expression(statement.Value, constants, global)
+
+	case *ast.LetPatternStatement:
+		statement.Value = o.expression(statement.Value, constants, global)
+
+	case *ast.ReturnStatement:
+		statement.ReturnValue = o.expression(statement.ReturnValue, constants, global)
+
+	case *ast.BlockStatement:
+		return o.block(statement, constants, global)
+
+	case *ast.FunctionStatement:
+		o.expression(statement.Function, constants, global)
+
+	case *ast.ThrowStatement:
+		statement.Value = o.expression(statement.Value, constants, global)
+
+	case *ast.ExportStatement:
+		statement.Declaration = o.statement(statement.Declaration, constants, global)
+
+	case *ast.TryStatement:
+		statement.Block = o.block(statement.Block, constants, global)
+		statement.CatchBlock = o.block(statement.CatchBlock, constants, global)
+		statement.FinallyBlock = o.block(statement.FinallyBlock, constants, global)
+
+	case *ast.ClassStatement:
+		// Methods see self and super, whatever constants of the same names are bound around the class.
+		methodConstants := localConstants(constants)
+		delete(methodConstants, object.SELF_NAME)
+		delete(methodConstants, object.SUPER_NAME)
+
+		for _, method := range statement.Methods {
+			method.Body = o.block(method.Body, methodConstants, false)
+		}
+	}
+
+	return statement
+}
+
+func (o *optimizer) block(block *ast.BlockStatement, constants map[string]constant, global bool) *ast.BlockStatement {
+	if block == nil {
+		return nil
+	}
+
+	block.Statements = o.statements(block.Statements, constants, global)
+	return block
+}
+
+func (o *optimizer) expression(exp ast.Expression, constants map[string]constant, global bool) ast.Expression {
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		if c, ok := constants[exp.Value]; ok {
+			return cloneLiteral(c.value)
+		}
+
+	case *ast.PrefixExpression:
+		exp.Right = o.expression(exp.Right, constants, global)
+		if isLiteral(exp.Right) {
+			return foldConstant(exp)
+		}
+
+	case *ast.InfixExpression:
+		exp.Left = o.expression(exp.Left, constants, global)
+		exp.Right = o.expression(exp.Right, constants, global)
+		if isLiteral(exp.Left) && isLiteral(exp.Right) {
+			return foldConstant(exp)
+		}
+
+	case *ast.IfExpression:
+		return o.conditionalExpression(exp, constants, global)
+
+	case *ast.FunctionLiteral:
+		exp.Body = o.block(exp.Body, localConstants(constants), false)
+
+	case *ast.CallExpression:
+		exp.Function = o.expression(exp.Function, constants, global)
+		for idx, arg := range exp.Arguments {
+			exp.Arguments[idx] = o.expression(arg, constants, global)
+		}
+
+	case *ast.StructLiteral:
+		for idx, value := range exp.Values {
+			exp.Values[idx] = o.expression(value, constants, global)
+		}
+
+	case *ast.MemberExpression:
+		exp.Object = o.expression(exp.Object, constants, global)
+
+	// An assigned name is left as it is, being bound more than once.
+	case *ast.AssignExpression:
+		if _, ok := exp.Target.(*ast.MemberExpression); ok {
+			exp.Target = o.expression(exp.Target, constants, global)
+		}
+		exp.Value = o.expression(exp.Value, constants, global)
+
+	case *ast.ArrayLiteral:
+		for idx, element := range exp.Elements {
+			exp.Elements[idx] = o.expression(element, constants, global)
+		}
+
+	case *ast.HashLiteral:
+		for idx, key := range exp.Keys {
+			exp.Keys[idx] = o.expression(key, constants, global)
+			exp.Values[idx] = o.expression(exp.Values[idx], constants, global)
+		}
+
+	case *ast.IndexExpression:
+		exp.Left = o.expression(exp.Left, constants, global)
+		exp.Index = o.expression(exp.Index, constants, global)
+
+	// Patterns are left as they are, and the names they bind hide constants of the same names.
+	case *ast.MatchExpression:
+		exp.Value = o.expression(exp.Value, constants, global)
+
+		for _, arm := range exp.Arms {
+			armConstants := copyConstants(constants)
+			for _, name := range ast.PatternNames(arm.Pattern) {
+				delete(armConstants, name.Value)
+			}
+
+			if arm.Guard != nil {
+				arm.Guard = o.expression(arm.Guard, armConstants, global)
+			}
+			arm.Body = o.expression(arm.Body, armConstants, global)
+		}
+	}
+
+	return exp
+}
+
+func (o *optimizer) conditionalExpression(exp *ast.IfExpression, constants map[string]constant, global bool) ast.Expression {
+	exp.Condition = o.expression(exp.Condition, constants, global)
+
+	if !isLiteral(exp.Condition) {
+		exp.Consequence = o.block(exp.Consequence, constants, global)
+		exp.Alternative = o.block(exp.Alternative, constants, global)
+		return exp
+	}
+
+	// From here on, only one of the branches can ever be evaluated.
+	if evalLiteral(exp.Condition).Truthy() {
+		exp.Consequence = o.block(exp.Consequence, constants, global)
+		exp.Alternative = nil
+
+		if value, ok := singleExpression(exp.Consequence); ok {
+			return value
+		}
+		return exp
+	}
+
+	exp.Consequence = &ast.BlockStatement{Token: exp.Consequence.Token, Statements: []ast.Statement{}}
+	exp.Alternative = o.block(exp.Alternative, constants, global)
+
+	if value, ok := singleExpression(exp.Alternative); ok {
+		return value
+	}
+	return exp
+}
+
+// Counts how many times each name is bound, by a let, an assignment or as a parameter, across the whole program.
+func (o *optimizer) countBindings(node ast.Node) {
+	switch node := node.(type) {
+	case *ast.Program:
+		for _, statement := range node.Statements {
+			o.countBindings(statement)
+		}
+
+	case *ast.BlockStatement:
+		for _, statement := range node.Statements {
+			o.countBindings(statement)
+		}
+
+	case *ast.ExpressionStatement:
+		o.countBindings(node.Expression)
+
+	case *ast.LetStatement:
+		o.bindings[node.Name.Value]++
+		o.countBindings(node.Value)
+
+	case *ast.LetPatternStatement:
+		for _, name := range ast.PatternNames(node.Pattern) {
+			o.bindings[name.Value]++
+		}
+		o.countBindings(node.Value)
+
+	case *ast.ReturnStatement:
+		o.countBindings(node.ReturnValue)
+
+	case *ast.StructStatement:
+		o.bindings[node.Name.Value]++
+
+	case *ast.FunctionStatement:
+		o.bindings[node.Name.Value]++
+		o.countBindings(node.Function)
+
+	case *ast.ThrowStatement:
+		o.countBindings(node.Value)
+
+	case *ast.ImportStatement:
+		o.bindings[node.Name.Value]++
+
+	case *ast.ExportStatement:
+		o.countBindings(node.Declaration)
+
+	case *ast.TryStatement:
+		o.countBindings(node.Block)
+		if node.CatchBlock != nil {
+			o.bindings[node.CatchParameter.Value]++
+			o.countBindings(node.CatchBlock)
+		}
+		if node.FinallyBlock != nil {
+			o.countBindings(node.FinallyBlock)
+		}
+
+	case *ast.ClassStatement:
+		o.bindings[node.Name.Value]++
+		for _, method := range node.Methods {
+			o.countBindings(method)
+		}
+
+	case *ast.PrefixExpression:
+		o.countBindings(node.Right)
+
+	case *ast.InfixExpression:
+		o.countBindings(node.Left)
+		o.countBindings(node.Right)
+
+	case *ast.IfExpression:
+		o.countBindings(node.Condition)
+		o.countBindings(node.Consequence)
+		if node.Alternative != nil {
+			o.countBindings(node.Alternative)
+		}
+
+	case *ast.FunctionLiteral:
+		for _, param := range node.Parameters {
+			o.bindings[param.Value]++
+		}
+		o.countBindings(node.Body)
+
+	case *ast.CallExpression:
+		o.countBindings(node.Function)
+		for _, arg := range node.Arguments {
+			o.countBindings(arg)
+		}
+
+	case *ast.StructLiteral:
+		o.countBindings(node.Struct)
+		for _, value := range node.Values {
+			o.countBindings(value)
+		}
+
+	case *ast.MemberExpression:
+		o.countBindings(node.Object)
+
+	// Assigning a name binds it again.
+	case *ast.AssignExpression:
+		if ident, ok := node.Target.(*ast.Identifier); ok {
+			o.bindings[ident.Value]++
+		}
+		o.countBindings(node.Target)
+		o.countBindings(node.Value)
+
+	case *ast.ArrayLiteral:
+		for _, element := range node.Elements {
+			o.countBindings(element)
+		}
+
+	case *ast.HashLiteral:
+		for idx, key := range node.Keys {
+			o.countBindings(key)
+			o.countBindings(node.Values[idx])
+		}
+
+	case *ast.IndexExpression:
+		o.countBindings(node.Left)
+		o.countBindings(node.Index)
+
+	case *ast.MatchExpression:
+		o.countBindings(node.Value)
+		for _, arm := range node.Arms {
+			for _, name := range ast.PatternNames(arm.Pattern) {
+				o.bindings[name.Value]++
+			}
+			if arm.Guard != nil {
+				o.countBindings(arm.Guard)
+			}
+			o.countBindings(arm.Body)
+		}
+	}
+}
+
+// Evaluates an operation on literals, and returns the literal of its result.
+// The operation is left as is if it errors, so that the error still happens at runtime.
+func foldConstant(exp ast.Expression) ast.Expression {
+	if literal, ok := toLiteral(eval.Eval(exp, object.NewEnvironment())); ok {
+		return literal
+	}
+
+	return exp
+}
+
+func evalLiteral(exp ast.Expression) object.Object {
+	return eval.Eval(exp, object.NewEnvironment())
+}
+
+func toLiteral(obj object.Object) (ast.Expression, bool) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return &ast.IntegerLiteral{
+			Token: token.Token{Type: token.INT, Literal: obj.Inspect()},
+			Value: obj.Value,
+		}, true
+
+	case *object.Boolean:
+		tokType := token.TokenType(token.FALSE)
+		if obj.Value {
+			tokType = token.TRUE
+		}
+		return &ast.Boolean{
+			Token: token.Token{Type: tokType, Literal: obj.Inspect()},
+			Value: obj.Value,
+		}, true
+
+	case *object.String:
+		return &ast.StringLiteral{
+			Token: token.Token{Type: token.STRING, Literal: obj.Value},
+			Value: obj.Value,
+		}, true
+	}
+
+	return nil, false
+}
+
+func isLiteral(exp ast.Expression) bool {
+	switch exp.(type) {
+	case *ast.IntegerLiteral, *ast.Boolean, *ast.StringLiteral:
+		return true
+	}
+
+	return false
+}
+
+func cloneLiteral(exp ast.Expression) ast.Expression {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		clone := *exp
+		return &clone
+	case *ast.Boolean:
+		clone := *exp
+		return &clone
+	case *ast.StringLiteral:
+		clone := *exp
+		return &clone
+	}
+
+	return exp
+}
+
+// Whether evaluating the statement can have no effect at all: a literal, or an if on a false constant without alternative.
+func isDeadStatement(statement ast.Statement) bool {
+	exp, ok := statement.(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+
+	if isLiteral(exp.Expression) {
+		return true
+	}
+
+	ifExp, ok := exp.Expression.(*ast.IfExpression)
+	return ok && isLiteral(ifExp.Condition) && !evalLiteral(ifExp.Condition).Truthy() && ifExp.Alternative == nil
+}
+
+// Returns the expression a block made of a single expression statement evaluates to.
+func singleExpression(block *ast.BlockStatement) (ast.Expression, bool) {
+	if block == nil || len(block.Statements) != 1 {
+		return nil, false
+	}
+
+	exp, ok := block.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		return nil, false
+	}
+
+	return exp.Expression, true
+}
+
+func copyConstants(constants map[string]constant) map[string]constant {
+	out := make(map[string]constant, len(constants))
+	for name, c := range constants {
+		out[name] = c
+	}
+
+	return out
+}
+
+func localConstants(constants map[string]constant) map[string]constant {
+	out := make(map[string]constant)
+	for name, c := range constants {
+		if !c.global {
+			out[name] = c
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,153 @@
+package optimize
+
+import (
+	"testing"
+
+	"github.com/MohamTahaB/interpreter-go/ast"
+	"github.com/MohamTahaB/interpreter-go/eval"
+	"github.com/MohamTahaB/interpreter-go/lexer"
+	"github.com/MohamTahaB/interpreter-go/object"
+	"github.com/MohamTahaB/interpreter-go/parser"
+)
+
+func TestConstantFolding(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2 * (5 + 10)", "30"},
+		{"-5", "-5"},
+		{"!true", "false"},
+		{"!0", "true"},
+		{"1 < 2", "true"},
+		{"true == false", "false"},
+		{`"hello" + " " + "world"`, "hello world"},
+		{"x + 2 * 3", "(x + 6)"},
+		{"fn(x) { x * (2 + 3) }", "fn(x) (x * 5)"},
+	}
+
+	for _, tt := range tests {
+		program := Optimize(parse(t, tt.input))
+
+		if program.String() != tt.expected {
+			t.Errorf("input %q: wrong optimized program. Expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestErrorsArePreserved(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5 / 0", "(5 / 0)"},
+		{"5 + true", "(5 + true)"},
+		{"-true", "(-true)"},
+		{`"Hello" - "World"`, "(Hello - World)"},
+		{"let zero = 0; 10 / zero", "let zero = 0;(10 / 0)"},
+	}
+
+	for _, tt := range tests {
+		program := Optimize(parse(t, tt.input))
+
+		if program.String() != tt.expected {
+			t.Errorf("input %q: wrong optimized program. Expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+
+		evaluated := eval.Eval(program, object.NewEnvironment())
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("input %q: no error object returned. Got=%T(%+v)", tt.input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestDeadBranchElimination(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"if (true) { 10 } else { 20 }", "10"},
+		{"if (false) { 10 } else { 20 }", "20"},
+		{"if (1 > 2) { 10 } else { 20 }", "20"},
+		{"if (true) { let a = 1; a } else { 20 }", "iftrue"},
+		{"if (false) { 10 }; 5", "5"},
+		{"if (x) { 1 + 1 } else { 3 }", "ifxelse 3"},
+	}
+
+	for _, tt := range tests {
+		program := Optimize(parse(t, tt.input))
+
+		if program.String() != tt.expected {
+			t.Errorf("input %q: wrong optimized program. Expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestConstantInlining(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let a = 5; let b = a * 2; b + 1", "let a = 5;let b = 10;11"},
+		// Bound twice, hence not constant.
+		{"let a = 5; let a = 6; a", "let a = 5;let a = 6;a"},
+		{"let a = 5; a = 6; a", "let a = 5;(a = 6)a"},
+		{"const a = 5; a * 2", "const a = 5;10"},
+		{"let a = 5; let f = fn(a) { a }; a", "let a = 5;let f = fn(a) a;a"},
+		// Globals are not inlined into functions, locals are.
+		{"let a = 5; fn() { a }", "let a = 5;fn() a"},
+		{"fn() { let a = 5; fn() { a } }", "fn() let a = 5;fn() 5"},
+		// Not inlined past the block binding it.
+		{"if (x) { let a = 5; a }; a", "ifxa"},
+		// Inlined into struct fields, but never as a member name.
+		{"let x = 2; struct P { x }; let p = P{x: x * 3}; p.x", "let x = 2;struct P { x }let p = P{x: 6};p.x"},
+	}
+
+	for _, tt := range tests {
+		program := Optimize(parse(t, tt.input))
+
+		if program.String() != tt.expected {
+			t.Errorf("input %q: wrong optimized program. Expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestOptimizedProgramsEvaluateTheSame(t *testing.T) {
+	inputs := []string{
+		"let a = 5; let b = a; let c = a + b + 5; c;",
+		"let add = fn(x, y) { x + y ;}; add(5 + 5, add(5, 5));",
+		"let newAdder = fn(x) { fn(y) { x + y + (2 * 3) } }; let addTwo = newAdder(2); addTwo(2);",
+		"if (10 > 1) { if (10 > 1) { return 10; } return 1; }",
+		"if (false) { 10 }",
+		"let f = fn(n) { let base = 10; if (n > base) { n } else { base - n } }; f(3) + f(30)",
+		"let a = 1; let f = fn() { a }; f()",
+		"struct P { x, y }; let p = P{x: 1 + 2, y: 3}; p.y = p.x * 2; p.y",
+		"fn() { let self = 1; let k = 2; class A { f() { self.g() + k } g() { 3 * 4 } }; A().f() }()",
+		"fn() { let r = f(); let k = 2; fn f() { k } r }()",
+		"fn() { let k = 2; let n = 1; match ([k, 3]) { [n, m] if n < m => n * 10 + m + k } }()",
+		"let xs = [1 + 1, 2 * 3]; {\"a\": xs[0] + xs[1]}[\"a\"]",
+		"let a = 1; let [a, b] = [2, a]; a * 10 + b",
+		"let a = 1; if (true) { let a = 2; a = 3 }; a",
+		"let a = 1; if (a > 0) { a = a + 1 }; a",
+	}
+
+	for _, input := range inputs {
+		expected := eval.Eval(parse(t, input), object.NewEnvironment())
+		got := eval.Eval(Optimize(parse(t, input)), object.NewEnvironment())
+
+		if got.Type() != expected.Type() || got.Inspect() != expected.Inspect() {
+			t.Errorf("input %q: optimized program evaluates differently. Expected=%s, got=%s", input, expected.Inspect(), got.Inspect())
+		}
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+
+	return program
+}
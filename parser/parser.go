@@ -2,7 +2,9 @@ package parser
 
 import (
 	"fmt"
+	"path"
 	"strconv"
+	"strings"
 
 	"github.com/MohamTahaB/interpreter-go/ast"
 	"github.com/MohamTahaB/interpreter-go/lexer"
@@ -29,12 +31,14 @@ type (
 const (
 	_ int = iota
 	LOWEST
+	ASSIGNMENT
 	EQUALS
 	LESSGREATER
 	SUM
 	PRODUCT
 	PREFIX
 	CALL
+	INDEX
 )
 
 // TODO: similarly: add the other infix ops later ...
@@ -43,11 +47,18 @@ var precedences = map[token.TokenType]int{
 	token.NEQ:          EQUALS,
 	token.LT:           LESSGREATER,
 	token.GT:           LESSGREATER,
+	token.LEQ:          LESSGREATER,
+	token.GEQ:          LESSGREATER,
+	token.IN:           LESSGREATER,
 	token.PLUS:         SUM,
 	token.MINUS:        SUM,
 	token.TIMES:        PRODUCT,
 	token.SLASH:        PRODUCT,
 	token.LPARENTHESIS: CALL,
+	token.LBRACE:       CALL,
+	token.DOT:          CALL,
+	token.ASSIGN:       ASSIGNMENT,
+	token.LBRACKET:     INDEX,
 }
 
 func New(l *lexer.Lexer) *Parser {
@@ -76,11 +87,21 @@ func New(l *lexer.Lexer) *Parser {
 
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 
+	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
+
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 
 	p.registerInfix(token.LPARENTHESIS, p.parseCallExpression)
+	p.registerInfix(token.LBRACE, p.parseStructLiteral)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.REGEX, p.parseRegexLiteral)
 	infixOperators := []token.TokenType{
 		token.PLUS,
 		token.MINUS,
@@ -90,6 +111,9 @@ func New(l *lexer.Lexer) *Parser {
 		token.NEQ,
 		token.LT,
 		token.GT,
+		token.LEQ,
+		token.GEQ,
+		token.IN,
 	}
 
 	for _, op := range infixOperators {
@@ -121,16 +145,37 @@ func (p *Parser) ParseProgram() *ast.Program {
 
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.currToken.Type {
-	case token.LET:
+	case token.LET, token.CONST:
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.STRUCT:
+		return p.parseStructStatement()
+	case token.CLASS:
+		return p.parseClassStatement()
+	case token.THROW:
+		return p.parseThrowStatement()
+	case token.TRY:
+		return p.parseTryStatement()
+	case token.IMPORT:
+		return p.parseImportStatement()
+	case token.EXPORT:
+		return p.parseExportStatement()
+	case token.FUNCTION:
+		if p.peekTokenIs(token.IDENT) {
+			return p.parseFunctionStatement()
+		}
+		return p.parseExpressionStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
-func (p *Parser) parseLetStatement() *ast.LetStatement {
+func (p *Parser) parseLetStatement() ast.Statement {
+	if p.peekTokenIs(token.LBRACKET) || p.peekTokenIs(token.LBRACE) {
+		return p.parseLetPatternStatement()
+	}
+
 	stmt := &ast.LetStatement{
 		Token: p.currToken,
 	}
@@ -152,6 +197,66 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 
 	stmt.Value = p.parseExpression(LOWEST)
 
+	// A function literal is named after the binding it is defined in.
+	if fn, ok := stmt.Value.(*ast.FunctionLiteral); ok && fn.Name == "" {
+		fn.Name = stmt.Name.Value
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseLetPatternStatement() *ast.LetPatternStatement {
+	stmt := &ast.LetPatternStatement{
+		Token: p.currToken,
+	}
+
+	p.nextToken()
+
+	if stmt.Pattern = p.parsePattern(); stmt.Pattern == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
+	stmt := &ast.FunctionStatement{
+		Token: p.currToken,
+	}
+
+	p.nextToken()
+
+	stmt.Name = &ast.Identifier{
+		Token: p.currToken,
+		Value: p.currToken.Literal,
+	}
+
+	// The literal is parsed from the name on, as from the fn keyword of an anonymous one.
+	fn, ok := p.parseFunctionLiteral().(*ast.FunctionLiteral)
+	if !ok {
+		return nil
+	}
+
+	fn.Token = stmt.Token
+	fn.Name = stmt.Name.Value
+	stmt.Function = fn
+
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
@@ -175,6 +280,252 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
+func (p *Parser) parseStructStatement() *ast.StructStatement {
+	stmt := &ast.StructStatement{
+		Token: p.currToken,
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{
+		Token: p.currToken,
+		Value: p.currToken.Literal,
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Fields = []*ast.Identifier{}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		stmt.Fields = append(stmt.Fields, &ast.Identifier{
+			Token: p.currToken,
+			Value: p.currToken.Literal,
+		})
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	p.nextToken()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseClassStatement() *ast.ClassStatement {
+	stmt := &ast.ClassStatement{
+		Token: p.currToken,
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{
+		Token: p.currToken,
+		Value: p.currToken.Literal,
+	}
+
+	if p.peekTokenIs(token.EXTENDS) {
+		p.nextToken()
+
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		stmt.Superclass = &ast.Identifier{
+			Token: p.currToken,
+			Value: p.currToken.Literal,
+		}
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	// Each method is written as a function literal, its name in place of the fn keyword.
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		stmt.MethodNames = append(stmt.MethodNames, &ast.Identifier{
+			Token: p.currToken,
+			Value: p.currToken.Literal,
+		})
+
+		method, ok := p.parseFunctionLiteral().(*ast.FunctionLiteral)
+		if !ok {
+			return nil
+		}
+
+		method.Name = stmt.Name.Value + "." + method.Token.Literal
+		stmt.Methods = append(stmt.Methods, method)
+	}
+
+	p.nextToken()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// Parses import "path" as name. Without a name, the module is named after its file, extension left out.
+func (p *Parser) parseImportStatement() ast.Statement {
+	stmt := &ast.ImportStatement{
+		Token: p.currToken,
+	}
+
+	if !p.expectPeek(token.STRING) {
+		return nil
+	}
+	stmt.Path = p.currToken.Literal
+
+	if p.peekTokenIs(token.AS) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	} else {
+		name := strings.TrimSuffix(path.Base(stmt.Path), path.Ext(stmt.Path))
+		if !isIdentifier(name) {
+			p.errors = append(p.errors, fmt.Sprintf("cannot name module %q after its file, expected as and a name", stmt.Path))
+			return nil
+		}
+		stmt.Name = &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// Whether the name lexes as a single identifier.
+func isIdentifier(name string) bool {
+	tok := lexer.New(name).NextToken()
+	return tok.Type == token.IDENT && tok.Literal == name
+}
+
+// Parses export followed by a declaration: a let, a const, a function declaration, a struct or a class.
+func (p *Parser) parseExportStatement() ast.Statement {
+	stmt := &ast.ExportStatement{
+		Token: p.currToken,
+	}
+
+	p.nextToken()
+
+	switch {
+	case p.currTokenIs(token.LET), p.currTokenIs(token.CONST), p.currTokenIs(token.STRUCT), p.currTokenIs(token.CLASS),
+		p.currTokenIs(token.FUNCTION) && p.peekTokenIs(token.IDENT):
+	default:
+		p.errors = append(p.errors, fmt.Sprintf("expected a declaration after export, got %s", p.currToken.Type))
+		return nil
+	}
+
+	errors := len(p.errors)
+	declaration := p.parseStatement()
+	if len(p.errors) != errors {
+		return nil
+	}
+	stmt.Declaration = declaration
+
+	return stmt
+}
+
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	stmt := &ast.ThrowStatement{
+		Token: p.currToken,
+	}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// Parses try { } catch (e) { } finally { }, where either the catch or the finally clause may be left out.
+func (p *Parser) parseTryStatement() *ast.TryStatement {
+	stmt := &ast.TryStatement{
+		Token: p.currToken,
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Block = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.CATCH) {
+		p.nextToken()
+
+		if !p.expectPeek(token.LPARENTHESIS) {
+			return nil
+		}
+
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		stmt.CatchParameter = &ast.Identifier{
+			Token: p.currToken,
+			Value: p.currToken.Literal,
+		}
+
+		if !p.expectPeek(token.RPARENTHESIS) {
+			return nil
+		}
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+
+		stmt.CatchBlock = p.parseBlockStatement()
+	}
+
+	if p.peekTokenIs(token.FINALLY) {
+		p.nextToken()
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+
+		stmt.FinallyBlock = p.parseBlockStatement()
+	}
+
+	if stmt.CatchBlock == nil && stmt.FinallyBlock == nil {
+		p.errors = append(p.errors, "expected catch or finally after try block")
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{Token: p.currToken}
 
@@ -339,6 +690,7 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	}
 
 	fn.Body = p.parseBlockStatement()
+	markTailCalls(fn.Body)
 
 	return fn
 }
@@ -349,7 +701,90 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 		Function: function,
 	}
 
-	exp.Arguments = p.parseCallArguments()
+	exp.Arguments = p.parseExpressionList(token.RPARENTHESIS)
+
+	return exp
+}
+
+func (p *Parser) parseStructLiteral(structExp ast.Expression) ast.Expression {
+	lit := &ast.StructLiteral{
+		Token:  p.currToken,
+		Struct: structExp,
+		Names:  []*ast.Identifier{},
+		Values: []ast.Expression{},
+	}
+
+	// Only a struct name can be constructed, which keeps any other expression followed by a brace an error.
+	if _, ok := structExp.(*ast.Identifier); !ok {
+		p.errors = append(p.errors, "expected a struct name before {")
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		lit.Names = append(lit.Names, &ast.Identifier{
+			Token: p.currToken,
+			Value: p.currToken.Literal,
+		})
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		lit.Values = append(lit.Values, p.parseExpression(LOWEST))
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	p.nextToken()
+
+	return lit
+}
+
+func (p *Parser) parseMemberExpression(object ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{
+		Token:  p.currToken,
+		Object: object,
+	}
+
+	// Keywords name members too, as in re.match.
+	if token.IsKeyword(p.peekToken.Type) {
+		p.nextToken()
+		p.currToken.Type = token.IDENT
+	} else if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	exp.Property = &ast.Identifier{
+		Token: p.currToken,
+		Value: p.currToken.Literal,
+	}
+
+	return exp
+}
+
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	exp := &ast.AssignExpression{
+		Token:  p.currToken,
+		Target: target,
+	}
+
+	switch target.(type) {
+	case *ast.Identifier, *ast.MemberExpression:
+	default:
+		p.errors = append(p.errors, "invalid assignment target, expected a name or a member")
+		return nil
+	}
+
+	// Assignments are right associative: a.x = b.y = 1 assigns b.y first.
+	p.nextToken()
+	exp.Value = p.parseExpression(ASSIGNMENT - 1)
 
 	return exp
 }
@@ -372,28 +807,29 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 
 }
 
-func (p *Parser) parseCallArguments() []ast.Expression {
-	args := []ast.Expression{}
+// Parses comma separated expressions up to the end token, as call arguments and array elements are.
+func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	list := []ast.Expression{}
 
-	if p.peekTokenIs(token.RPARENTHESIS) {
+	if p.peekTokenIs(end) {
 		p.nextToken()
-		return args
+		return list
 	}
 
 	p.nextToken()
-	args = append(args, p.parseExpression(LOWEST))
+	list = append(list, p.parseExpression(LOWEST))
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
+		list = append(list, p.parseExpression(LOWEST))
 	}
 
-	if !p.expectPeek(token.RPARENTHESIS) {
+	if !p.expectPeek(end) {
 		return nil
 	}
 
-	return args
+	return list
 }
 
 func (p *Parser) parseFunctionParameters() []*ast.Identifier {
@@ -434,6 +870,69 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 
 }
 
+// Marks the calls of a function body whose value is returned as is: the value of the last statement, and return values.
+// Such calls can be made once the function returned, without growing the stack.
+func markTailCalls(body *ast.BlockStatement) {
+	if body == nil {
+		return
+	}
+
+	for idx, stmt := range body.Statements {
+		last := idx == len(body.Statements)-1
+
+		switch stmt := stmt.(type) {
+		case *ast.ReturnStatement:
+			markTailPosition(stmt.ReturnValue)
+
+		case *ast.ExpressionStatement:
+			if last {
+				markTailPosition(stmt.Expression)
+				continue
+			}
+
+			// Return statements nested in a conditional still end the function.
+			if ifExp, ok := stmt.Expression.(*ast.IfExpression); ok {
+				markTailReturns(ifExp.Consequence)
+				markTailReturns(ifExp.Alternative)
+			}
+		}
+	}
+}
+
+func markTailPosition(exp ast.Expression) {
+	switch exp := exp.(type) {
+	case *ast.CallExpression:
+		exp.Tail = true
+	case *ast.IfExpression:
+		markTailCalls(exp.Consequence)
+		markTailCalls(exp.Alternative)
+	case *ast.MatchExpression:
+		for _, arm := range exp.Arms {
+			markTailPosition(arm.Body)
+		}
+	}
+}
+
+// Same as markTailCalls, for blocks whose value is dropped, where only return statements are in tail position.
+func markTailReturns(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+
+	for _, stmt := range block.Statements {
+		switch stmt := stmt.(type) {
+		case *ast.ReturnStatement:
+			markTailPosition(stmt.ReturnValue)
+
+		case *ast.ExpressionStatement:
+			if ifExp, ok := stmt.Expression.(*ast.IfExpression); ok {
+				markTailReturns(ifExp.Consequence)
+				markTailReturns(ifExp.Alternative)
+			}
+		}
+	}
+}
+
 func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{
 		Token: p.currToken,
@@ -441,6 +940,18 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	}
 }
 
+// The flags of the regex come after its last slash.
+func (p *Parser) parseRegexLiteral() ast.Expression {
+	literal := p.currToken.Literal
+	end := strings.LastIndexByte(literal, '/')
+
+	return &ast.RegexLiteral{
+		Token:   p.currToken,
+		Pattern: literal[1:end],
+		Flags:   literal[end+1:],
+	}
+}
+
 func (p *Parser) expectPeek(tokType token.TokenType) bool {
 	if p.peekTokenIs(tokType) {
 		p.nextToken()
@@ -481,3 +992,222 @@ func (p *Parser) peekError(tokType token.TokenType) {
 
 	p.errors = append(p.errors, msg)
 }
+
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	return &ast.ArrayLiteral{
+		Token:    p.currToken,
+		Elements: p.parseExpressionList(token.RBRACKET),
+	}
+}
+
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{
+		Token:  p.currToken,
+		Keys:   []ast.Expression{},
+		Values: []ast.Expression{},
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		hash.Keys = append(hash.Keys, p.parseExpression(LOWEST))
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		hash.Values = append(hash.Values, p.parseExpression(LOWEST))
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	p.nextToken()
+
+	return hash
+}
+
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	exp := &ast.IndexExpression{
+		Token: p.currToken,
+		Left:  left,
+	}
+
+	p.nextToken()
+	exp.Index = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return exp
+}
+
+// match (value) { pattern if guard => body, ... }, arms being separated by commas.
+func (p *Parser) parseMatchExpression() ast.Expression {
+	exp := &ast.MatchExpression{
+		Token: p.currToken,
+		Arms:  []*ast.MatchArm{},
+	}
+
+	if !p.expectPeek(token.LPARENTHESIS) {
+		return nil
+	}
+
+	p.nextToken()
+	exp.Value = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPARENTHESIS) || !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		arm := &ast.MatchArm{Pattern: p.parsePattern()}
+		if arm.Pattern == nil {
+			return nil
+		}
+
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			p.nextToken()
+			arm.Guard = p.parseExpression(LOWEST)
+		}
+
+		if !p.expectPeek(token.ARROW) {
+			return nil
+		}
+
+		p.nextToken()
+		arm.Body = p.parseExpression(LOWEST)
+		exp.Arms = append(exp.Arms, arm)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	p.nextToken()
+
+	return exp
+}
+
+// Parses the pattern starting at the current token.
+func (p *Parser) parsePattern() ast.Expression {
+	switch p.currToken.Type {
+	case token.IDENT:
+		return &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+
+	case token.INT, token.STRING, token.TRUE, token.FALSE:
+		return p.prefixParseFns[p.currToken.Type]()
+
+	// Negative integers
+	case token.MINUS:
+		exp := &ast.PrefixExpression{Token: p.currToken, Operator: token.MINUS}
+		if !p.expectPeek(token.INT) {
+			return nil
+		}
+		exp.Right = p.parseIntegerLiteral()
+		return exp
+
+	case token.LBRACKET:
+		return p.parseArrayPattern()
+
+	case token.LBRACE:
+		return p.parseHashPattern()
+	}
+
+	p.errors = append(p.errors, fmt.Sprintf("unexpected %s in pattern", p.currToken.Type))
+	return nil
+}
+
+func (p *Parser) parseArrayPattern() ast.Expression {
+	pattern := &ast.ArrayPattern{
+		Token:    p.currToken,
+		Elements: []ast.Expression{},
+	}
+
+	for !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+
+		// The rest of the elements can only be bound last.
+		if p.currTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+
+			pattern.Rest = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+			break
+		}
+
+		element := p.parsePattern()
+		if element == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, element)
+
+		if !p.peekTokenIs(token.RBRACKET) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return pattern
+}
+
+// Keys are literals, as in a hash literal.
+func (p *Parser) parseHashPattern() ast.Expression {
+	pattern := &ast.HashPattern{
+		Token:  p.currToken,
+		Keys:   []ast.Expression{},
+		Values: []ast.Expression{},
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		// A lone name is bound to the value of the key of the same name.
+		if p.currTokenIs(token.IDENT) && (p.peekTokenIs(token.COMMA) || p.peekTokenIs(token.RBRACE)) {
+			pattern.Keys = append(pattern.Keys, &ast.StringLiteral{Token: p.currToken, Value: p.currToken.Literal})
+			pattern.Values = append(pattern.Values, &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal})
+
+			if p.peekTokenIs(token.COMMA) {
+				p.nextToken()
+			}
+			continue
+		}
+
+		switch p.currToken.Type {
+		case token.INT, token.STRING, token.TRUE, token.FALSE:
+			pattern.Keys = append(pattern.Keys, p.prefixParseFns[p.currToken.Type]())
+		default:
+			p.errors = append(p.errors, fmt.Sprintf("unexpected %s as hash pattern key", p.currToken.Type))
+			return nil
+		}
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+
+		value := p.parsePattern()
+		if value == nil {
+			return nil
+		}
+		pattern.Values = append(pattern.Values, value)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	p.nextToken()
+
+	return pattern
+}
@@ -278,6 +278,14 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"3 + 4 * 5 == 3 * 1 + 4 * 5",
 			"((3 + (4 * 5)) == ((3 * 1) + (4 * 5)))",
 		},
+		{
+			"a <= b == c >= d",
+			"((a <= b) == (c >= d))",
+		},
+		{
+			"a + b in c == true",
+			"(((a + b) in c) == true)",
+		},
 		{
 			"-1 * 2 + 3;",
 			"(((-1) * 2) + 3)",
@@ -330,6 +338,18 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a + b + c * d / f + g)",
 			"add((((a + b) + ((c * d) / f)) + g))",
 		},
+		{
+			"a * [1, 2, 3, 4][b * c] * d",
+			"((a * ([1, 2, 3, 4][(b * c)])) * d)",
+		},
+		{
+			"add(a * b[2], b[1], 2 * [1, 2][1])",
+			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
+		},
+		{
+			"p.xs[0] + f(1)[2]",
+			"((p.xs[0]) + (f(1)[2]))",
+		},
 	}
 	for _, tt := range tests {
 		l := lexer.New(tt.input)
@@ -600,6 +620,45 @@ func TestCallExpressionParsing(t *testing.T) {
 	}
 }
 
+func TestTailCallMarking(t *testing.T) {
+	input := `fn(n) {
+		if (n == 0) { return f(0); }
+		let x = g(n);
+		if (n > 1) { h(n) } else { n + k(n) }
+	}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	fn := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+
+	returned := fn.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IfExpression).
+		Consequence.Statements[0].(*ast.ReturnStatement).ReturnValue.(*ast.CallExpression)
+	bound := fn.Body.Statements[1].(*ast.LetStatement).Value.(*ast.CallExpression)
+	last := fn.Body.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	consequence := last.Consequence.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	added := last.Alternative.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.InfixExpression).
+		Right.(*ast.CallExpression)
+
+	tests := []struct {
+		call     *ast.CallExpression
+		expected bool
+	}{
+		{returned, true},
+		{bound, false},
+		{consequence, true},
+		{added, false},
+	}
+
+	for _, tt := range tests {
+		if tt.call.Tail != tt.expected {
+			t.Errorf("call %s wrongly marked. Expected Tail=%t, got=%t", tt.call, tt.expected, tt.call.Tail)
+		}
+	}
+}
+
 func TestLetStatements(t *testing.T) {
 	tests := []struct {
 		input              string
@@ -641,6 +700,40 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestConstStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		constant bool
+	}{
+		{"const x = 5;", "const x = 5;", true},
+		{"let x = 5;", "let x = 5;", false},
+		{"const [a, ...b] = xs", "const [a, ...b] = xs;", true},
+		{"const {name} = p", "const {name: name} = p;", true},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+
+		var constant bool
+		switch stmt := program.Statements[0].(type) {
+		case *ast.LetStatement:
+			constant = stmt.Constant()
+		case *ast.LetPatternStatement:
+			constant = stmt.Constant()
+		}
+		if constant != tt.constant {
+			t.Errorf("input %q: expected constant=%t, got=%t", tt.input, tt.constant, constant)
+		}
+	}
+}
+
 func TestStringLiteralExpression(t *testing.T) {
 	input := `"hello world";`
 
@@ -661,6 +754,439 @@ func TestStringLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestStructStatementParsing(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedName   string
+		expectedFields []string
+	}{
+		{"struct Point { x, y }", "Point", []string{"x", "y"}},
+		{"struct Empty {};", "Empty", []string{}},
+		{"struct Pair { first, second, }", "Pair", []string{"first", "second"}},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. Got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.StructStatement)
+		if !ok {
+			t.Fatalf("statement is not *ast.StructStatement. Got=%T", program.Statements[0])
+		}
+
+		if stmt.Name.Value != tt.expectedName {
+			t.Errorf("wrong struct name. Expected=%q, got=%q", tt.expectedName, stmt.Name.Value)
+		}
+
+		if len(stmt.Fields) != len(tt.expectedFields) {
+			t.Fatalf("wrong number of fields. Expected=%d, got=%d", len(tt.expectedFields), len(stmt.Fields))
+		}
+		for idx, field := range tt.expectedFields {
+			testIdentifier(t, stmt.Fields[idx], field)
+		}
+	}
+}
+
+func TestStructExpressionsParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Point{x: 1, y: 2}", "Point{x: 1, y: 2}"},
+		{"Point{}", "Point{}"},
+		{"Point{x: 1 + 2, y: f(3)}", "Point{x: (1 + 2), y: f(3)}"},
+		{"p.x", "p.x"},
+		{"a.b.c", "a.b.c"},
+		{"re.match(x)", "re.match(x)"},
+		{"p.x + q.y * 2", "(p.x + (q.y * 2))"},
+		{"-p.x", "(-p.x)"},
+		{"f(p).x", "f(p).x"},
+		{"p.f(1)", "p.f(1)"},
+		{"Point{x: 1}.x", "Point{x: 1}.x"},
+		{"p.x = 1 + 2", "(p.x = (1 + 2))"},
+		{"p.x = q.y = 3", "(p.x = (q.y = 3))"},
+		{"p.ok = a == b", "(p.ok = (a == b))"},
+		{"x = y = 1", "(x = (y = 1))"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestStructExpressionsErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"1 = 5", "invalid assignment target, expected a name or a member"},
+		{"f(1) = 5", "invalid assignment target, expected a name or a member"},
+		{"(a + b){x: 1}", "expected a struct name before {"},
+		{`x."if"`, "expected next token to be IDENT, got STRING instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected a parser error", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. Expected=%q, got=%q", tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestClassStatementParsing(t *testing.T) {
+	tests := []struct {
+		input      string
+		expected   string
+		superclass string
+		methods    []string
+	}{
+		{
+			"class Point { init(x, y) { self.x = x; self.y = y; } sum() { self.x + self.y } }",
+			"class Point { init(x, y) (self.x = x)(self.y = y) sum() (self.x + self.y) }",
+			"",
+			[]string{"init", "sum"},
+		},
+		{
+			"class Empty extends Base {};",
+			"class Empty extends Base { }",
+			"Base",
+			[]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. Got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ClassStatement)
+		if !ok {
+			t.Fatalf("statement is not *ast.ClassStatement. Got=%T", program.Statements[0])
+		}
+
+		if stmt.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, stmt.String())
+		}
+
+		if tt.superclass == "" && stmt.Superclass != nil {
+			t.Errorf("unexpected superclass %s", stmt.Superclass)
+		}
+		if tt.superclass != "" {
+			testIdentifier(t, stmt.Superclass, tt.superclass)
+		}
+
+		if len(stmt.Methods) != len(tt.methods) {
+			t.Fatalf("wrong number of methods. Expected=%d, got=%d", len(tt.methods), len(stmt.Methods))
+		}
+		for idx, name := range tt.methods {
+			testIdentifier(t, stmt.MethodNames[idx], name)
+		}
+	}
+}
+
+func TestFunctionStatementParsing(t *testing.T) {
+	p := New(lexer.New("fn add(a, b) { a + b }; let sub = fn(a, b) { a - b }; fn(x) { x }"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 3 {
+		t.Fatalf("program.Statements does not contain 3 statements. Got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.FunctionStatement)
+	if !ok {
+		t.Fatalf("statement is not *ast.FunctionStatement. Got=%T", program.Statements[0])
+	}
+
+	testIdentifier(t, stmt.Name, "add")
+	if expected := "fn add(a, b) (a + b)"; stmt.String() != expected {
+		t.Errorf("expected=%q, got=%q", expected, stmt.String())
+	}
+
+	names := []struct {
+		fn       *ast.FunctionLiteral
+		expected string
+	}{
+		{stmt.Function, "add"},
+		{program.Statements[1].(*ast.LetStatement).Value.(*ast.FunctionLiteral), "sub"},
+		{program.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral), ""},
+	}
+
+	for _, tt := range names {
+		if tt.fn.Name != tt.expected {
+			t.Errorf("function has wrong name. Expected=%q, got=%q", tt.expected, tt.fn.Name)
+		}
+	}
+}
+
+func TestCollectionLiteralsParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[]", "[]"},
+		{"[1, 2 * 2, \"a\"]", "[1, (2 * 2), a]"},
+		{"{}", "{}"},
+		{"{\"one\": 1, 2: 1 + 1, true: [3]}", "{one: 1, 2: (1 + 1), true: [3]}"},
+		{"let h = {\"k\": fn(x) { x }}; h[\"k\"](1)", "let h = {k: fn(x) x};(h[k])(1)"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestMatchExpressionParsing(t *testing.T) {
+	input := `match (x) {
+		1 => "one",
+		-1 => "minus one",
+		"a" => a,
+		[a, _, [b]] if a > b => a - b,
+		{"k": v, 2: true} => v,
+		n => n * 2,
+	}`
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. Got=%d", len(program.Statements))
+	}
+
+	exp, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("expression is not *ast.MatchExpression. Got=%T", program.Statements[0].(*ast.ExpressionStatement).Expression)
+	}
+
+	expected := "match (x) { 1 => one, (-1) => minus one, a => a, [a, _, [b]] if (a > b) => (a - b), {k: v, 2: true} => v, n => (n * 2) }"
+	if exp.String() != expected {
+		t.Errorf("expected=%q, got=%q", expected, exp.String())
+	}
+
+	if exp.Arms[3].Guard == nil || exp.Arms[4].Guard != nil {
+		t.Errorf("guards wrongly parsed")
+	}
+
+	names := ast.PatternNames(exp.Arms[3].Pattern)
+	if len(names) != 2 || names[0].Value != "a" || names[1].Value != "b" {
+		t.Errorf("wrong pattern names. Got=%v", names)
+	}
+}
+
+func TestMatchExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match (x) { 1 + 2 => 3 }", "expected next token to be =>, got + instead"},
+		{"match (x) { f(1) => 3 }", "expected next token to be =>, got ( instead"},
+		{"match (x) { (1) => 3 }", "unexpected ( in pattern"},
+		{"match (x) { {k: 1} => 3 }", "unexpected IDENT as hash pattern key"},
+		{"match (x) { 1 => 2 3 => 4 }", "expected next token to be ,, got INT instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong errors for %q. Expected first=%q, got=%v", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestLetPatternParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		names    []string
+	}{
+		{"let [a, b] = xs;", "let [a, b] = xs;", []string{"a", "b"}},
+		{"let [a, _, ...rest] = f(1)", "let [a, _, ...rest] = f(1);", []string{"a", "rest"}},
+		{"let [...all] = xs", "let [...all] = xs;", []string{"all"}},
+		{"let {name, age} = person;", "let {name: name, age: age} = person;", []string{"name", "age"}},
+		{"let {\"k\": [x, y], name} = h", "let {k: [x, y], name: name} = h;", []string{"x", "y", "name"}},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.LetPatternStatement)
+		if !ok {
+			t.Fatalf("statement is not *ast.LetPatternStatement. Got=%T", program.Statements[0])
+		}
+
+		if stmt.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, stmt.String())
+		}
+
+		names := ast.PatternNames(stmt.Pattern)
+		if len(names) != len(tt.names) {
+			t.Fatalf("wrong number of names. Expected=%d, got=%d", len(tt.names), len(names))
+		}
+		for idx, name := range tt.names {
+			testIdentifier(t, names[idx], name)
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, ...rest, b] = xs", "expected next token to be ], got , instead"},
+		{"let [...] = xs", "expected next token to be IDENT, got ] instead"},
+		{"let [a] xs", "expected next token to be =, got IDENT instead"},
+	}
+
+	for _, tt := range errors {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong errors for %q. Expected first=%q, got=%v", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestImportExportParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		names    []string
+	}{
+		{`import "lib/util.mky" as u;`, `import "lib/util.mky" as u;`, []string{"u"}},
+		{`import "./strings"`, `import "./strings" as strings;`, []string{"strings"}},
+		{"export let x = 1;", "export let x = 1;", []string{"x"}},
+		{"export const [a, ...b] = xs", "export const [a, ...b] = xs;", []string{"a", "b"}},
+		{"export fn add(a, b) { a + b }", "export fn add(a, b) (a + b)", []string{"add"}},
+		{"export struct P { x }", "export struct P { x }", []string{"P"}},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+
+		var names []*ast.Identifier
+		switch stmt := program.Statements[0].(type) {
+		case *ast.ImportStatement:
+			names = []*ast.Identifier{stmt.Name}
+		case *ast.ExportStatement:
+			names = stmt.Names()
+		}
+
+		if len(names) != len(tt.names) {
+			t.Fatalf("input %q: wrong number of names. Expected=%d, got=%d", tt.input, len(tt.names), len(names))
+		}
+		for idx, name := range tt.names {
+			testIdentifier(t, names[idx], name)
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"import lib", "expected next token to be STRING, got IDENT instead"},
+		{`import "lib" as "l"`, "expected next token to be IDENT, got STRING instead"},
+		{`import "my-lib.mky"`, `cannot name module "my-lib.mky" after its file, expected as and a name`},
+		{`import "match"`, `cannot name module "match" after its file, expected as and a name`},
+		{"export 1 + 2", "expected a declaration after export, got INT"},
+		{"export fn(x) { x }", "expected a declaration after export, got FUNCTION"},
+	}
+
+	for _, tt := range errors {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong errors for %q. Expected first=%q, got=%v", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestTryThrowParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"throw 1 + 2;", "throw (1 + 2);"},
+		{"try { f() } catch (e) { e }", "try { f() } catch (e) { e }"},
+		{"try { f() } finally { g() }", "try { f() } finally { g() }"},
+		{"try { f() } catch (e) { e } finally { g() };", "try { f() } catch (e) { e } finally { g() }"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	p := New(lexer.New("try { f() }"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 || p.Errors()[0] != "expected catch or finally after try block" {
+		t.Errorf("wrong errors for a lone try block. Got=%v", p.Errors())
+	}
+}
+
+func TestNoTailCallsInTry(t *testing.T) {
+	p := New(lexer.New("fn() { try { return f(); } catch (e) { g() } }"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	fn := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	try := fn.Body.Statements[0].(*ast.TryStatement)
+
+	call := try.Block.Statements[0].(*ast.ReturnStatement).ReturnValue.(*ast.CallExpression)
+	if call.Tail {
+		t.Errorf("call returned from a try block is marked as a tail call")
+	}
+
+	call = try.CatchBlock.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	if call.Tail {
+		t.Errorf("call in a catch block is marked as a tail call")
+	}
+}
+
 func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
 	if s.TokenLiteral() != "let" {
 		t.Errorf("s.TokenLiteral not 'let'. Got=%q", s.TokenLiteral())
@@ -818,3 +1344,42 @@ func testBooleanLiteral(t *testing.T, exp ast.Expression, value bool) bool {
 
 	return true
 }
+
+func TestRegexLiteralParsing(t *testing.T) {
+	tests := []struct {
+		input   string
+		pattern string
+		flags   string
+	}{
+		{`/ab+c/`, "ab+c", ""},
+		{`/a\/b/is`, `a\/b`, "is"},
+		{`//`, "", ""},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		regex, ok := stmt.Expression.(*ast.RegexLiteral)
+		if !ok {
+			t.Fatalf("input %q: expression is not *ast.RegexLiteral. got=%T", tt.input, stmt.Expression)
+		}
+
+		if regex.Pattern != tt.pattern || regex.Flags != tt.flags {
+			t.Errorf("input %q: wrong regex. Expected pattern=%q flags=%q, got pattern=%q flags=%q", tt.input, tt.pattern, tt.flags, regex.Pattern, regex.Flags)
+		}
+		if regex.String() != tt.input {
+			t.Errorf("input %q: wrong string. got=%q", tt.input, regex.String())
+		}
+	}
+
+	// Divisions stay divisions.
+	p := New(lexer.New("a / b / c"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if actual := program.String(); actual != "((a / b) / c)" {
+		t.Errorf("expected=%q, got=%q", "((a / b) / c)", actual)
+	}
+}
@@ -2,18 +2,46 @@ package repl
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 
+	"github.com/MohamTahaB/interpreter-go/ast"
+	"github.com/MohamTahaB/interpreter-go/compiler"
 	"github.com/MohamTahaB/interpreter-go/eval"
 	"github.com/MohamTahaB/interpreter-go/lexer"
 	"github.com/MohamTahaB/interpreter-go/object"
+	"github.com/MohamTahaB/interpreter-go/optimize"
 	"github.com/MohamTahaB/interpreter-go/parser"
+	"github.com/MohamTahaB/interpreter-go/resolver"
+	"github.com/MohamTahaB/interpreter-go/vm"
 )
 
 const PROMPT = ">> "
 
+// Engines the REPL can run programs with.
+const (
+	ENGINE_EVAL = "eval"
+	ENGINE_VM   = "vm"
+)
+
+type Config struct {
+	Engine string
+
+	// Whether programs go through the optimize passes before running.
+	Optimize bool
+
+	// Directories searched for imported modules, after the importing module's own.
+	SearchPath []string
+
+	// Directory the fs module is confined to. Programs cannot import fs without one.
+	Root string
+
+	// Seed of the random module, drawn from the clock if nil. Zero is a seed like any other.
+	Seed *int64
+}
+
 const ERROR_HEADER = `
                              ud$$$**$$$$$$$bc.
                           u@**"        4$$$$$$$Nu
@@ -62,9 +90,15 @@ const ERROR_HEADER = `
 ITS NOT LOOKING GOOD BRUV !!! Had some issues:
 `
 
-func Start(in io.Reader, out io.Writer) {
+// Printed before the error of a program the vm engine cannot compile, which was parsed fine.
+const COMPILE_ERROR_HEADER = "The vm engine cannot compile the program:"
+
+// Printed after the error of a program using a construct the vm engine does not support.
+const UNSUPPORTED_HINT = "The vm engine runs a subset of the language, see the README. Run the program with -engine=eval instead."
+
+func Start(in io.Reader, out io.Writer, cfg Config) {
 	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
+	run := newRunner(cfg, "", out)
 
 	for {
 		fmt.Print(PROMPT)
@@ -80,22 +114,98 @@ func Start(in io.Reader, out io.Writer) {
 			return
 		}
 
-		l := lexer.New(line)
-		p := parser.New(l)
+		evaluated, ok := execute(line, out, run, cfg)
+		if ok && evaluated != nil {
+			io.WriteString(out, evaluated.Inspect())
+			io.WriteString(out, "\n")
+		}
+	}
+}
+
+// Runs a whole program, such as a script file read from the path, which its relative imports are resolved from.
+// The program prints to out, and errOut gets its errors if it fails. Returns whether it ran without error.
+func Run(path, source string, out, errOut io.Writer, cfg Config) bool {
+	_, ok := execute(source, errOut, newRunner(cfg, path, out), cfg)
+	return ok
+}
 
-		program := p.ParseProgram()
+// Parses and runs the source, printing its parse errors, its compile error, or the traceback of the runtime error it raised, to out.
+// Returns the value of the program, and whether it ran without error.
+func execute(source string, out io.Writer, run func(*ast.Program) (object.Object, error), cfg Config) (object.Object, bool) {
+	l := lexer.New(source)
+	p := parser.New(l)
 
-		if len(p.Errors()) != 0 {
-			printParseErrors(out, p.Errors())
-			continue
-		}
+	program := p.ParseProgram()
 
-		evaluated := eval.Eval(program, env)
-		if evaluated != nil {
-			io.WriteString(out, evaluated.Inspect())
-			io.WriteString(out, "\n")
+	if len(p.Errors()) != 0 {
+		printParseErrors(out, p.Errors())
+		return nil, false
+	}
+
+	if cfg.Optimize {
+		program = optimize.Optimize(program)
+	}
+
+	evaluated, err := run(program)
+	if err != nil {
+		printCompileError(out, err)
+		return nil, false
+	}
+
+	if errObj, ok := evaluated.(*object.Error); ok {
+		io.WriteString(out, errObj.Traceback())
+		io.WriteString(out, "\n")
+		return nil, false
+	}
+
+	return evaluated, true
+}
+
+// Returns the function running the programs entered in the REPL with the configured engine, as the main module of the file at the path, if any.
+// Each engine keeps its state from one line to the next. Programs print to out.
+func newRunner(cfg Config, path string, out io.Writer) func(*ast.Program) (object.Object, error) {
+	eval.Output = out
+
+	if cfg.Engine == ENGINE_VM {
+		constants := []object.Object{}
+		globals := make([]object.Object, vm.GlobalsSize)
+		symbolTable := compiler.NewSymbolTable()
+
+		return func(program *ast.Program) (object.Object, error) {
+			comp := compiler.NewWithState(symbolTable, constants)
+			if err := comp.Compile(program); err != nil {
+				return nil, err
+			}
+
+			bytecode := comp.Bytecode()
+			constants = bytecode.Constants
+
+			return vm.NewWithGlobalsStore(bytecode, globals).Run(), nil
 		}
 	}
+
+	loader := eval.NewLoader(cfg.SearchPath...)
+	if cfg.Root != "" {
+		loader.Provide(eval.NewFSModule(cfg.Root))
+	}
+	if cfg.Seed != nil {
+		loader.Provide(eval.NewRandomModule(*cfg.Seed))
+	}
+
+	env := loader.Main(path).Env
+	return func(program *ast.Program) (object.Object, error) {
+		resolver.Resolve(program)
+		return eval.Eval(program, env), nil
+	}
+}
+
+func printCompileError(out io.Writer, err error) {
+	io.WriteString(out, fmt.Sprintf("%s\n\t%s\n", COMPILE_ERROR_HEADER, err))
+
+	var unsupported *compiler.UnsupportedError
+	if errors.As(err, &unsupported) {
+		io.WriteString(out, UNSUPPORTED_HINT+"\n")
+	}
 }
 
 func printParseErrors(out io.Writer, errors []string) {
@@ -0,0 +1,311 @@
+package resolver
+
+import (
+	"github.com/MohamTahaB/interpreter-go/ast"
+	"github.com/MohamTahaB/interpreter-go/object"
+)
+
+// Bindings of one function call environment.
+type scope struct {
+	slots map[string]int
+
+	// Function literals and class methods met in the scope, resolved once every binding of the scope is known, as the evaluator looks bindings up at call time.
+	pending []pendingFunction
+
+	// Set for the environments that are not frames, whose bindings are looked up by name:
+	// the one binding self and super around a method, the one binding the names of a match arm's pattern, and the one of a block.
+	byName bool
+}
+
+// Function literal left to resolve, with the scopes it is defined in.
+type pendingFunction struct {
+	fn     *ast.FunctionLiteral
+	scopes []*scope
+
+	// Set for class methods, which run within the environment their instance binds self and super in.
+	method bool
+}
+
+type Resolver struct {
+	// Innermost scope last. The program's own scope is the global one, whose bindings are left to be looked up by name.
+	scopes []*scope
+}
+
+// Annotates the identifiers of the program bound in a function with their (depth, slot) position, and each function literal with its frame size.
+func Resolve(program *ast.Program) {
+	r := &Resolver{}
+	r.resolveScope(program.Statements, nil)
+}
+
+// Resolves a list of statements, declaring their bindings in a new scope, then the functions defined in it.
+// A nil function stands for the global scope.
+func (r *Resolver) resolveScope(statements []ast.Statement, fn *ast.FunctionLiteral) {
+	s := &scope{slots: make(map[string]int)}
+	r.scopes = append(r.scopes, s)
+
+	if fn != nil {
+		for _, param := range fn.Parameters {
+			r.declare(param)
+		}
+	}
+
+	r.hoist(statements)
+	for _, statement := range statements {
+		r.resolve(statement)
+	}
+
+	// Functions defined in the scope may define functions in turn, hence the index based loop.
+	for i := 0; i < len(s.pending); i++ {
+		r.resolveFunction(s.pending[i])
+	}
+
+	if fn != nil {
+		fn.FrameSize = len(s.slots)
+	}
+
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+// Leaves the function to the innermost frame, with a copy of the scopes it is defined in.
+func (r *Resolver) postpone(fn *ast.FunctionLiteral, method bool) {
+	frame := len(r.scopes) - 1
+	for r.scopes[frame].byName {
+		frame--
+	}
+
+	scopes := make([]*scope, len(r.scopes))
+	copy(scopes, r.scopes)
+	r.scopes[frame].pending = append(r.scopes[frame].pending, pendingFunction{fn: fn, scopes: scopes, method: method})
+}
+
+// Resolves a function literal within the scopes it is defined in, which may be more than the current ones, as for a function defined in a block.
+func (r *Resolver) resolveFunction(pending pendingFunction) {
+	current := r.scopes
+	r.scopes = pending.scopes
+
+	if pending.method {
+		bound := &scope{slots: map[string]int{object.SELF_NAME: 0, object.SUPER_NAME: 1}, byName: true}
+		r.scopes = append(r.scopes, bound)
+	}
+
+	r.resolveScope(pending.fn.Body.Statements, pending.fn)
+	r.scopes = current
+}
+
+func (r *Resolver) resolve(node ast.Node) {
+	switch node := node.(type) {
+	// Statements
+	case *ast.ExpressionStatement:
+		r.resolve(node.Expression)
+
+	case *ast.BlockStatement:
+		r.resolveBlock(node)
+
+	case *ast.LetStatement:
+		// The value is resolved first, so that it still sees any outer binding of the same name.
+		r.resolve(node.Value)
+		r.declare(node.Name)
+
+	case *ast.LetPatternStatement:
+		r.resolve(node.Value)
+		for _, name := range ast.PatternNames(node.Pattern) {
+			r.declare(name)
+		}
+
+	case *ast.ReturnStatement:
+		r.resolve(node.ReturnValue)
+
+	// Declared as its block was entered.
+	case *ast.FunctionStatement:
+		r.resolve(node.Function)
+
+	case *ast.StructStatement:
+		r.declare(node.Name)
+
+	case *ast.ThrowStatement:
+		r.resolve(node.Value)
+
+	case *ast.ImportStatement:
+		r.declare(node.Name)
+
+	case *ast.ExportStatement:
+		r.resolve(node.Declaration)
+
+	case *ast.TryStatement:
+		r.resolve(node.Block)
+		if node.CatchBlock != nil {
+			r.resolveBlock(node.CatchBlock, node.CatchParameter)
+		}
+		if node.FinallyBlock != nil {
+			r.resolve(node.FinallyBlock)
+		}
+
+	case *ast.ClassStatement:
+		if node.Superclass != nil {
+			r.lookup(node.Superclass)
+		}
+		r.declare(node.Name)
+
+		for _, method := range node.Methods {
+			r.postpone(method, true)
+		}
+
+	// Expressions
+	case *ast.Identifier:
+		r.lookup(node)
+
+	case *ast.PrefixExpression:
+		r.resolve(node.Right)
+
+	case *ast.InfixExpression:
+		r.resolve(node.Left)
+		r.resolve(node.Right)
+
+	case *ast.IfExpression:
+		r.resolve(node.Condition)
+		r.resolve(node.Consequence)
+		if node.Alternative != nil {
+			r.resolve(node.Alternative)
+		}
+
+	// Left to the innermost frame, whose bindings must all be known first.
+	case *ast.FunctionLiteral:
+		r.postpone(node, false)
+
+	case *ast.CallExpression:
+		r.resolve(node.Function)
+		for _, arg := range node.Arguments {
+			r.resolve(arg)
+		}
+
+	case *ast.StructLiteral:
+		r.resolve(node.Struct)
+		for _, value := range node.Values {
+			r.resolve(value)
+		}
+
+	// The property is a name, not a binding.
+	case *ast.MemberExpression:
+		r.resolve(node.Object)
+
+	case *ast.AssignExpression:
+		r.resolve(node.Target)
+		r.resolve(node.Value)
+
+	case *ast.ArrayLiteral:
+		for _, element := range node.Elements {
+			r.resolve(element)
+		}
+
+	case *ast.HashLiteral:
+		for idx, key := range node.Keys {
+			r.resolve(key)
+			r.resolve(node.Values[idx])
+		}
+
+	case *ast.IndexExpression:
+		r.resolve(node.Left)
+		r.resolve(node.Index)
+
+	case *ast.MatchExpression:
+		r.resolve(node.Value)
+		for _, arm := range node.Arms {
+			r.resolveArm(arm)
+		}
+	}
+}
+
+// Resolves the statements of a block within its own environment, which binds the given names besides the block's own.
+func (r *Resolver) resolveBlock(block *ast.BlockStatement, names ...*ast.Identifier) {
+	r.scopes = append(r.scopes, &scope{slots: make(map[string]int), byName: true})
+
+	for _, name := range names {
+		r.declare(name)
+	}
+
+	r.hoist(block.Statements)
+	for _, statement := range block.Statements {
+		r.resolve(statement)
+	}
+
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+// Resolves the guard and body of a match arm within the environment binding the names of its pattern.
+func (r *Resolver) resolveArm(arm *ast.MatchArm) {
+	bound := &scope{slots: make(map[string]int), byName: true}
+	for _, name := range ast.PatternNames(arm.Pattern) {
+		bound.slots[name.Value] = len(bound.slots)
+	}
+
+	r.scopes = append(r.scopes, bound)
+	if arm.Guard != nil {
+		r.resolve(arm.Guard)
+	}
+	r.resolve(arm.Body)
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+// Declares the functions of a list of statements before resolving any of them, as the evaluator binds them first.
+func (r *Resolver) hoist(statements []ast.Statement) {
+	for _, statement := range statements {
+		if export, ok := statement.(*ast.ExportStatement); ok {
+			statement = export.Declaration
+		}
+
+		if declaration, ok := statement.(*ast.FunctionStatement); ok {
+			r.declare(declaration.Name)
+		}
+	}
+}
+
+// Binds the identifier in the innermost scope. Binding a name twice in a scope reuses its slot, as the evaluator overwrites it.
+// In a scope whose bindings are looked up by name, the name is only recorded, so that it hides outer bindings.
+func (r *Resolver) declare(ident *ast.Identifier) {
+	if len(r.scopes) == 1 {
+		return
+	}
+
+	current := r.scopes[len(r.scopes)-1]
+
+	slot, ok := current.slots[ident.Value]
+	if !ok {
+		slot = len(current.slots)
+		current.slots[ident.Value] = slot
+	}
+
+	if current.byName {
+		ident.Resolved = false
+		return
+	}
+
+	ident.Resolved = true
+	ident.Depth = 0
+	ident.Slot = slot
+}
+
+func (r *Resolver) lookup(ident *ast.Identifier) {
+	r.lookupFrom(ident, len(r.scopes)-1)
+}
+
+// Resolves the identifier to its innermost binding in the scopes up to the given index, then its fallback to the next one out.
+func (r *Resolver) lookupFrom(ident *ast.Identifier, from int) {
+	// The global scope, at index 0, is left out.
+	for i := from; i > 0; i-- {
+		if slot, ok := r.scopes[i].slots[ident.Value]; ok {
+			if r.scopes[i].byName {
+				break
+			}
+
+			ident.Resolved = true
+			ident.Depth = len(r.scopes) - 1 - i
+			ident.Slot = slot
+			ident.Fallback = &ast.Identifier{Token: ident.Token, Value: ident.Value}
+			r.lookupFrom(ident.Fallback, i-1)
+			return
+		}
+	}
+
+	ident.Resolved = false
+	ident.Fallback = nil
+}
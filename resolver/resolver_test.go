@@ -0,0 +1,247 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/MohamTahaB/interpreter-go/ast"
+	"github.com/MohamTahaB/interpreter-go/lexer"
+	"github.com/MohamTahaB/interpreter-go/parser"
+)
+
+func TestResolveIdentifiers(t *testing.T) {
+	input := `
+	let g = 1;
+	let outer = fn(a, b) {
+		let c = a + b;
+		fn(d) { a + c + d + g };
+	};
+	`
+
+	program := parse(t, input)
+	Resolve(program)
+
+	outer := program.Statements[1].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+	if outer.FrameSize != 3 {
+		t.Errorf("outer function has wrong frame size. Expected=3, got=%d", outer.FrameSize)
+	}
+
+	inner := outer.Body.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if inner.FrameSize != 1 {
+		t.Errorf("inner function has wrong frame size. Expected=1, got=%d", inner.FrameSize)
+	}
+
+	// a + c + d + g parses as (((a + c) + d) + g)
+	sum := inner.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.InfixExpression)
+	g := sum.Right.(*ast.Identifier)
+	d := sum.Left.(*ast.InfixExpression).Right.(*ast.Identifier)
+	c := sum.Left.(*ast.InfixExpression).Left.(*ast.InfixExpression).Right.(*ast.Identifier)
+	a := sum.Left.(*ast.InfixExpression).Left.(*ast.InfixExpression).Left.(*ast.Identifier)
+
+	tests := []struct {
+		ident    *ast.Identifier
+		resolved bool
+		depth    int
+		slot     int
+	}{
+		{a, true, 1, 0},
+		{c, true, 1, 2},
+		{d, true, 0, 0},
+		{g, false, 0, 0},
+	}
+
+	for _, tt := range tests {
+		if tt.ident.Resolved != tt.resolved {
+			t.Errorf("identifier %s: wrong resolution. Expected=%t, got=%t", tt.ident, tt.resolved, tt.ident.Resolved)
+			continue
+		}
+		if tt.ident.Depth != tt.depth || tt.ident.Slot != tt.slot {
+			t.Errorf("identifier %s: wrong position. Expected=(%d, %d), got=(%d, %d)",
+				tt.ident, tt.depth, tt.slot, tt.ident.Depth, tt.ident.Slot)
+		}
+	}
+}
+
+func TestResolveLaterBindings(t *testing.T) {
+	// The inner function refers to a binding defined after it, which the evaluator finds at call time.
+	input := `
+	fn() {
+		let f = fn() { x };
+		let x = 5;
+		let x = 6;
+		f();
+	};
+	`
+
+	program := parse(t, input)
+	Resolve(program)
+
+	outer := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if outer.FrameSize != 2 {
+		t.Errorf("function has wrong frame size. Expected=2, got=%d", outer.FrameSize)
+	}
+
+	f := outer.Body.Statements[0].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+	x := f.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.Identifier)
+	if !x.Resolved || x.Depth != 1 || x.Slot != 1 {
+		t.Errorf("x wrongly resolved. Got=(%t, %d, %d)", x.Resolved, x.Depth, x.Slot)
+	}
+}
+
+func TestResolveHoistedFunctions(t *testing.T) {
+	// The declared function is bound from the start of the body, before the let.
+	input := `
+	fn() {
+		let x = f();
+		fn f() { 1 }
+	};
+	`
+
+	program := parse(t, input)
+	Resolve(program)
+
+	outer := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	let := outer.Body.Statements[0].(*ast.LetStatement)
+	f := let.Value.(*ast.CallExpression).Function.(*ast.Identifier)
+
+	if !f.Resolved || f.Depth != 0 || f.Slot != 0 {
+		t.Errorf("f wrongly resolved. Got=(%t, %d, %d)", f.Resolved, f.Depth, f.Slot)
+	}
+	if let.Name.Slot != 1 {
+		t.Errorf("x has wrong slot. Expected=1, got=%d", let.Name.Slot)
+	}
+}
+
+func TestResolveMatchArms(t *testing.T) {
+	// Names bound by a pattern are looked up by name, and the arm's environment counts in the depth of the others.
+	input := `
+	fn(a, k) {
+		match (a) { [x] => fn() { x + k } }
+	};
+	`
+
+	program := parse(t, input)
+	Resolve(program)
+
+	outer := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	match := outer.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.MatchExpression)
+
+	value := match.Value.(*ast.Identifier)
+	if !value.Resolved || value.Depth != 0 || value.Slot != 0 {
+		t.Errorf("a wrongly resolved. Got=(%t, %d, %d)", value.Resolved, value.Depth, value.Slot)
+	}
+
+	inner := match.Arms[0].Body.(*ast.FunctionLiteral)
+	sum := inner.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.InfixExpression)
+
+	x := sum.Left.(*ast.Identifier)
+	if x.Resolved {
+		t.Errorf("x is resolved, though bound by a pattern")
+	}
+
+	k := sum.Right.(*ast.Identifier)
+	if !k.Resolved || k.Depth != 2 || k.Slot != 1 {
+		t.Errorf("k wrongly resolved. Got=(%t, %d, %d)", k.Resolved, k.Depth, k.Slot)
+	}
+}
+
+func TestResolveBlocks(t *testing.T) {
+	// A block's bindings are looked up by name, take no slot of the frame, and the block's environment counts in the depth of the others.
+	input := `
+	fn(a) {
+		if (a) { let b = a; fn() { a + b } }
+	};
+	`
+
+	program := parse(t, input)
+	Resolve(program)
+
+	outer := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if outer.FrameSize != 1 {
+		t.Errorf("function has wrong frame size. Expected=1, got=%d", outer.FrameSize)
+	}
+
+	block := outer.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IfExpression).Consequence
+	let := block.Statements[0].(*ast.LetStatement)
+	if let.Name.Resolved {
+		t.Errorf("b is resolved, though bound in a block")
+	}
+
+	value := let.Value.(*ast.Identifier)
+	if !value.Resolved || value.Depth != 1 || value.Slot != 0 {
+		t.Errorf("a wrongly resolved in the block. Got=(%t, %d, %d)", value.Resolved, value.Depth, value.Slot)
+	}
+
+	inner := block.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	sum := inner.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.InfixExpression)
+
+	a := sum.Left.(*ast.Identifier)
+	if !a.Resolved || a.Depth != 2 || a.Slot != 0 {
+		t.Errorf("a wrongly resolved in the closure. Got=(%t, %d, %d)", a.Resolved, a.Depth, a.Slot)
+	}
+	if b := sum.Right.(*ast.Identifier); b.Resolved {
+		t.Errorf("b is resolved, though bound in a block")
+	}
+}
+
+func TestResolveMethods(t *testing.T) {
+	// The method sees self by name, and the function's local one environment further than in a function literal.
+	input := `
+	fn(self) {
+		let n = 1;
+		class C { get(x) { self.v + n + x } };
+	};
+	`
+
+	program := parse(t, input)
+	Resolve(program)
+
+	outer := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	class := outer.Body.Statements[1].(*ast.ClassStatement)
+	if !class.Name.Resolved || class.Name.Slot != 2 {
+		t.Errorf("class name has wrong position. Got resolved=%t, slot=%d", class.Name.Resolved, class.Name.Slot)
+	}
+
+	method := class.Methods[0]
+	if method.FrameSize != 1 {
+		t.Errorf("method has wrong frame size. Expected=1, got=%d", method.FrameSize)
+	}
+
+	// self.v + n + x parses as ((self.v + n) + x)
+	sum := method.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.InfixExpression)
+	x := sum.Right.(*ast.Identifier)
+	n := sum.Left.(*ast.InfixExpression).Right.(*ast.Identifier)
+	self := sum.Left.(*ast.InfixExpression).Left.(*ast.MemberExpression).Object.(*ast.Identifier)
+
+	tests := []struct {
+		ident    *ast.Identifier
+		resolved bool
+		depth    int
+		slot     int
+	}{
+		{x, true, 0, 0},
+		{n, true, 2, 1},
+		{self, false, 0, 0},
+	}
+
+	for _, tt := range tests {
+		if tt.ident.Resolved != tt.resolved {
+			t.Errorf("identifier %s: wrong resolution. Expected=%t, got=%t", tt.ident, tt.resolved, tt.ident.Resolved)
+			continue
+		}
+		if tt.ident.Depth != tt.depth || tt.ident.Slot != tt.slot {
+			t.Errorf("identifier %s: wrong position. Expected=(%d, %d), got=(%d, %d)",
+				tt.ident, tt.depth, tt.slot, tt.ident.Depth, tt.ident.Slot)
+		}
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+
+	return program
+}
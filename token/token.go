@@ -5,6 +5,9 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+
+	// Position of the first char of the token in the source, both starting at 1. Zero for tokens not read from a source.
+	Line, Column int
 }
 
 // Define different token types in the language
@@ -36,22 +39,43 @@ const (
 	MINUSEQ = "-="
 	SLASHEQ = "/="
 	TIMESEQ = "*="
+	ARROW   = "=>"
 
 	// Delimiters
 	COMMA     = ","
 	SEMICOLON = ";"
+	COLON     = ":"
+	DOT       = "."
+	ELLIPSIS  = "..."
 
 	LPARENTHESIS = "("
 	RPARENTHESIS = ")"
 	LBRACE       = "{"
 	RBRACE       = "}"
+	LBRACKET     = "["
+	RBRACKET     = "]"
 
 	// Keywords
 	FUNCTION = "FUNCTION"
 	LET      = "LET"
+	CONST    = "CONST"
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	STRUCT   = "STRUCT"
+	CLASS    = "CLASS"
+	EXTENDS  = "EXTENDS"
+	THROW    = "THROW"
+	TRY      = "TRY"
+	CATCH    = "CATCH"
+	FINALLY  = "FINALLY"
+	MATCH    = "MATCH"
+	IMPORT   = "IMPORT"
+	EXPORT   = "EXPORT"
+	AS       = "AS"
+
+	// Keyword operators, whose type is their literal, as the evaluator looks operators up by literal.
+	IN = "in"
 
 	// Booleans
 	TRUE  = "TRUE"
@@ -59,6 +83,9 @@ const (
 
 	// String
 	STRING = "STRING"
+
+	// Regex literal, /pattern/flags
+	REGEX = "REGEX"
 )
 
 var ONE_CHAR_TOKEN_LITTERALS map[byte]bool = map[byte]bool{
@@ -72,10 +99,14 @@ var ONE_CHAR_TOKEN_LITTERALS map[byte]bool = map[byte]bool{
 	'!': true,
 	';': true,
 	',': true,
+	':': true,
+	'.': true,
 	'(': true,
 	')': true,
 	'{': true,
 	'}': true,
+	'[': true,
+	']': true,
 	0:   true,
 }
 
@@ -113,6 +144,10 @@ func CharToToken(ch byte) TokenType {
 		tt = COMMA
 	case ';':
 		tt = SEMICOLON
+	case ':':
+		tt = COLON
+	case '.':
+		tt = DOT
 	case '(':
 		tt = LPARENTHESIS
 	case ')':
@@ -121,6 +156,10 @@ func CharToToken(ch byte) TokenType {
 		tt = LBRACE
 	case '}':
 		tt = RBRACE
+	case '[':
+		tt = LBRACKET
+	case ']':
+		tt = RBRACKET
 	case 0:
 		tt = EOF
 	default:
@@ -136,13 +175,37 @@ func LegalOneCharLiteral(ch byte) bool {
 }
 
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
-	"true":   TRUE,
-	"false":  FALSE,
+	"fn":      FUNCTION,
+	"let":     LET,
+	"const":   CONST,
+	"if":      IF,
+	"else":    ELSE,
+	"return":  RETURN,
+	"struct":  STRUCT,
+	"class":   CLASS,
+	"extends": EXTENDS,
+	"throw":   THROW,
+	"try":     TRY,
+	"catch":   CATCH,
+	"finally": FINALLY,
+	"match":   MATCH,
+	"import":  IMPORT,
+	"export":  EXPORT,
+	"as":      AS,
+	"true":    TRUE,
+	"false":   FALSE,
+	"in":      IN,
+}
+
+// Whether the token type is the one of a keyword.
+func IsKeyword(t TokenType) bool {
+	for _, keyword := range keywords {
+		if keyword == t {
+			return true
+		}
+	}
+
+	return false
 }
 
 func LookupIdent(ident string) TokenType {
@@ -0,0 +1,25 @@
+package vm
+
+import (
+	"github.com/MohamTahaB/interpreter-go/compiler"
+	"github.com/MohamTahaB/interpreter-go/object"
+)
+
+// Call frame of the closure being executed.
+type Frame struct {
+	cl          *object.Closure
+	ip          int
+	basePointer int
+}
+
+func NewFrame(cl *object.Closure, basePointer int) *Frame {
+	return &Frame{
+		cl:          cl,
+		ip:          -1,
+		basePointer: basePointer,
+	}
+}
+
+func (f *Frame) Instructions() compiler.Instructions {
+	return f.cl.Fn.Instructions
+}
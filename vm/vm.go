@@ -0,0 +1,357 @@
+package vm
+
+import (
+	"github.com/MohamTahaB/interpreter-go/compiler"
+	"github.com/MohamTahaB/interpreter-go/eval"
+	"github.com/MohamTahaB/interpreter-go/object"
+)
+
+// The stack and the frames grow on demand, from StackSize slots up to MaxStackSize, and up to MaxFrames nested calls.
+const (
+	StackSize    = 2048
+	MaxStackSize = 1 << 20
+	GlobalsSize  = 65536
+	MaxFrames    = 1 << 16
+)
+
+const (
+	STACK_OVERFLOW = "stack overflow"
+	WRONG_ARGS_NB  = eval.WRONG_ARGS_NB
+	UNKNOWN_OPCODE = "unknown opcode: %d"
+)
+
+type VM struct {
+	constants []object.Object
+
+	globals     []object.Object
+	globalNames []string
+
+	stack []object.Object
+	sp    int // Always points to the next free slot. Top of stack is stack[sp-1]
+
+	frames      []*Frame
+	framesIndex int
+
+	lastPopped object.Object
+}
+
+func New(bytecode *compiler.Bytecode) *VM {
+	mainFn := &object.CompiledFunction{Instructions: bytecode.Instructions}
+	mainClosure := &object.Closure{Fn: mainFn}
+	mainFrame := NewFrame(mainClosure, 0)
+
+	frames := []*Frame{mainFrame}
+
+	return &VM{
+		constants: bytecode.Constants,
+
+		globals:     make([]object.Object, GlobalsSize),
+		globalNames: bytecode.GlobalNames,
+
+		stack: make([]object.Object, StackSize),
+		sp:    0,
+
+		frames:      frames,
+		framesIndex: 1,
+	}
+}
+
+// Returns a virtual machine running on a previously used globals store, as the REPL needs.
+func NewWithGlobalsStore(bytecode *compiler.Bytecode, globals []object.Object) *VM {
+	vm := New(bytecode)
+	vm.globals = globals
+	return vm
+}
+
+// Runs the bytecode, and returns the value of the program, as eval.Eval would.
+// Runtime errors halt the machine and are returned as an object.Error.
+func (vm *VM) Run() object.Object {
+	var ip int
+	var ins compiler.Instructions
+	var op compiler.Opcode
+
+	for vm.currentFrame().ip < len(vm.currentFrame().Instructions())-1 {
+		vm.currentFrame().ip++
+
+		ip = vm.currentFrame().ip
+		ins = vm.currentFrame().Instructions()
+		op = compiler.Opcode(ins[ip])
+
+		var err *object.Error
+
+		switch op {
+		case compiler.OpConstant:
+			constIndex := compiler.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			err = vm.push(vm.constants[constIndex])
+
+		case compiler.OpPop:
+			vm.lastPopped = vm.pop()
+
+		case compiler.OpTrue:
+			err = vm.push(eval.TRUE)
+
+		case compiler.OpFalse:
+			err = vm.push(eval.FALSE)
+
+		case compiler.OpNull:
+			err = vm.push(eval.NULL)
+
+		case compiler.OpAdd, compiler.OpSub, compiler.OpMul, compiler.OpDiv,
+			compiler.OpEqual, compiler.OpNotEqual,
+			compiler.OpLessThan, compiler.OpLessEqual, compiler.OpGreaterThan, compiler.OpGreaterEqual,
+			compiler.OpIn:
+			err = vm.executeInfixOperation(op)
+
+		case compiler.OpMinus, compiler.OpBang:
+			err = vm.executePrefixOperation(op)
+
+		case compiler.OpJump:
+			pos := int(compiler.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip = pos - 1
+
+		case compiler.OpJumpNotTruthy:
+			pos := int(compiler.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+
+			condition := vm.pop()
+			if !condition.Truthy() {
+				vm.currentFrame().ip = pos - 1
+			}
+
+		case compiler.OpSetGlobal:
+			globalIndex := compiler.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			vm.globals[globalIndex] = vm.pop()
+
+		case compiler.OpGetGlobal:
+			globalIndex := compiler.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			// An unbound global may name a builtin, as in the evaluator.
+			global := vm.globals[globalIndex]
+			if global == nil {
+				name := vm.globalNames[globalIndex]
+				builtin, ok := eval.BUILTINS[name]
+				if !ok {
+					err = newError(eval.IDENT_NOT_FOUND, name)
+					break
+				}
+				global = builtin
+			}
+			err = vm.push(global)
+
+		case compiler.OpSetLocal:
+			localIndex := compiler.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			frame := vm.currentFrame()
+			vm.stack[frame.basePointer+int(localIndex)] = vm.pop()
+
+		case compiler.OpGetLocal:
+			localIndex := compiler.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			frame := vm.currentFrame()
+			local := vm.stack[frame.basePointer+int(localIndex)]
+			if local == nil {
+				err = newError(eval.IDENT_NOT_FOUND, frame.cl.Fn.LocalNames[localIndex])
+				break
+			}
+			err = vm.push(local)
+
+		case compiler.OpGetFree:
+			freeIndex := compiler.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			err = vm.push(vm.currentFrame().cl.Free[freeIndex])
+
+		case compiler.OpCurrentClosure:
+			err = vm.push(vm.currentFrame().cl)
+
+		case compiler.OpClosure:
+			constIndex := compiler.ReadUint16(ins[ip+1:])
+			numFree := compiler.ReadUint8(ins[ip+3:])
+			vm.currentFrame().ip += 3
+
+			err = vm.pushClosure(int(constIndex), int(numFree))
+
+		case compiler.OpCall:
+			numArgs := compiler.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			err = vm.callFunction(int(numArgs))
+
+		case compiler.OpReturnValue:
+			returnValue := vm.pop()
+
+			// Returning from the program itself halts the machine.
+			if vm.framesIndex == 1 {
+				return returnValue
+			}
+
+			frame := vm.popFrame()
+			vm.sp = frame.basePointer - 1
+
+			err = vm.push(returnValue)
+
+		case compiler.OpReturn:
+			frame := vm.popFrame()
+			vm.sp = frame.basePointer - 1
+
+			err = vm.push(eval.NULL)
+
+		default:
+			err = newError(UNKNOWN_OPCODE, op)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return vm.lastPopped
+}
+
+// Returns the last element popped off the stack, that is the value of the last expression statement.
+func (vm *VM) LastPoppedStackElem() object.Object {
+	return vm.lastPopped
+}
+
+func (vm *VM) executeInfixOperation(op compiler.Opcode) *object.Error {
+	right := vm.pop()
+	left := vm.pop()
+
+	return vm.pushResult(object.ApplyInfix(compiler.INFIX_OPCODES_OPERATORS[op], left, right))
+}
+
+func (vm *VM) executePrefixOperation(op compiler.Opcode) *object.Error {
+	right := vm.pop()
+
+	return vm.pushResult(object.ApplyPrefix(compiler.PREFIX_OPCODES_OPERATORS[op], right))
+}
+
+// Pushes the result of an operation, unless it is an error, in which case the machine has to halt.
+func (vm *VM) pushResult(result object.Object) *object.Error {
+	if errObj, ok := result.(*object.Error); ok {
+		return errObj
+	}
+
+	return vm.push(result)
+}
+
+func (vm *VM) callFunction(numArgs int) *object.Error {
+	callee := vm.stack[vm.sp-1-numArgs]
+
+	// A builtin runs right away, its result taking the place of the callee and its arguments.
+	if builtin, ok := callee.(*object.Builtin); ok {
+		result := builtin.Fn(vm.stack[vm.sp-numArgs : vm.sp]...)
+		vm.sp = vm.sp - 1 - numArgs
+		return vm.pushResult(result)
+	}
+
+	cl, ok := callee.(*object.Closure)
+	if !ok {
+		return newError(eval.NOT_A_FUNC, callee.Type())
+	}
+
+	if numArgs != cl.Fn.NumParameters {
+		return newError(WRONG_ARGS_NB, cl.Fn.NumParameters, numArgs)
+	}
+
+	if vm.framesIndex >= MaxFrames {
+		return newError(STACK_OVERFLOW)
+	}
+
+	frame := NewFrame(cl, vm.sp-numArgs)
+	vm.pushFrame(frame)
+
+	// Reserve the locals, cleared so that reading an unset one is reported.
+	newSp := frame.basePointer + cl.Fn.NumLocals
+	if err := vm.growStack(newSp); err != nil {
+		return err
+	}
+	for i := frame.basePointer + cl.Fn.NumParameters; i < newSp; i++ {
+		vm.stack[i] = nil
+	}
+	vm.sp = newSp
+
+	return nil
+}
+
+func (vm *VM) pushClosure(constIndex, numFree int) *object.Error {
+	constant := vm.constants[constIndex]
+	function, ok := constant.(*object.CompiledFunction)
+	if !ok {
+		return newError(eval.NOT_A_FUNC, constant.Type())
+	}
+
+	free := make([]object.Object, numFree)
+	copy(free, vm.stack[vm.sp-numFree:vm.sp])
+	vm.sp = vm.sp - numFree
+
+	return vm.push(&object.Closure{Fn: function, Free: free})
+}
+
+func (vm *VM) push(obj object.Object) *object.Error {
+	if err := vm.growStack(vm.sp + 1); err != nil {
+		return err
+	}
+
+	vm.stack[vm.sp] = obj
+	vm.sp++
+
+	return nil
+}
+
+// Makes room for size slots on the stack, doubling it as needed, unless that takes more than MaxStackSize.
+func (vm *VM) growStack(size int) *object.Error {
+	if size <= len(vm.stack) {
+		return nil
+	}
+	if size > MaxStackSize {
+		return newError(STACK_OVERFLOW)
+	}
+
+	stack := make([]object.Object, min(max(2*len(vm.stack), size), MaxStackSize))
+	copy(stack, vm.stack)
+	vm.stack = stack
+
+	return nil
+}
+
+func (vm *VM) pop() object.Object {
+	obj := vm.stack[vm.sp-1]
+	vm.sp--
+	return obj
+}
+
+func (vm *VM) currentFrame() *Frame {
+	return vm.frames[vm.framesIndex-1]
+}
+
+func (vm *VM) pushFrame(f *Frame) {
+	if vm.framesIndex == len(vm.frames) {
+		vm.frames = append(vm.frames, f)
+	} else {
+		vm.frames[vm.framesIndex] = f
+	}
+	vm.framesIndex++
+}
+
+func (vm *VM) popFrame() *Frame {
+	vm.framesIndex--
+	return vm.frames[vm.framesIndex]
+}
+
+// Returns the error with the kind the evaluator gives the same message, so that both engines report it alike.
+func newError(format string, a ...interface{}) *object.Error {
+	err := object.NewError(format, a...)
+	if kind, ok := eval.ERROR_KINDS[format]; ok {
+		err.Kind = kind
+	}
+
+	return err
+}
@@ -0,0 +1,257 @@
+package vm
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/MohamTahaB/interpreter-go/ast"
+	"github.com/MohamTahaB/interpreter-go/compiler"
+	"github.com/MohamTahaB/interpreter-go/eval"
+	"github.com/MohamTahaB/interpreter-go/lexer"
+	"github.com/MohamTahaB/interpreter-go/object"
+	"github.com/MohamTahaB/interpreter-go/parser"
+)
+
+// Inputs of the evaluator tests, that the virtual machine must evaluate to the same result.
+var parityInputs = []string{
+	// Integers
+	"5", "69", "-5", "-69",
+	"5 + 5 + 5 + 5 - 10",
+	"2 * 2 * 2 * 2 * 2",
+	"-50 + 100 + -50",
+	"5 * 2 + 10",
+	"5 + 2 * 10",
+	"20 + 2 * -10",
+	"50 / 2 * 2 + 10",
+	"2 * (5 + 10)",
+	"3 * 3 * 3 + 10",
+	"3 * (3 * 3) + 10",
+	"(5 + 10 * 2 + 15 / 3) * 2 + -10",
+
+	// Booleans
+	"true", "false",
+	"1 < 2", "1 > 2", "1 < 1", "1 > 1",
+	"1 == 1", "1 != 1", "1 == 2", "1 != 2",
+	"true == true", "false == false", "true == false", "true != false", "false != true",
+	"(1 < 2) == true", "(1 < 2) == false", "(1 > 2) == true", "(1 > 2) == false",
+
+	// Conditionals
+	"if (true) { 10 }",
+	"if (false) { 10 }",
+	"if (1) { 10 }",
+	"if (1 < 2) { 10 }",
+	"if (1 > 2) { 10 }",
+	"if (1 > 2) { 10 } else { 20 }",
+	"if (1 < 2) { 10 } else { 20 }",
+
+	// Returns
+	"return 10;",
+	"return 10; 11",
+	"return 2*5; true",
+	"9; return 10;",
+	"return true;",
+	"10; return false;",
+	`
+	if (10 > 1) {
+		if (10 > 1) {
+			return 10;
+		}
+		return 1;
+	}
+	`,
+
+	// Errors
+	"5 + true;",
+	"5 + true; 5;",
+	"-true;",
+	"true + false;",
+	"5; true + false; 5",
+	"if (10 > 1) { true + false; }",
+	`if (10 > 1) {
+		if (10 > 1) {
+			return true + false;
+		}
+		return 1;
+	}`,
+	"foobar",
+	`"Hello" - "World"`,
+	"5 / 0",
+
+	// Negation
+	"!true", "!false", "!5", "!!true", "!!false", "!!5", "!0", "!!0",
+
+	// Let statements
+	"let a = 5; a;",
+	"let a = 5 * 5; a;",
+	"let a = 5; let b = a; b;",
+	"let a = 5; let b = a; let c = a + b + 5; c;",
+	"let a = 5;",
+
+	// Functions
+	"fn(x) { x + 2; };",
+	"let identity = fn(x) { x; }; identity(5);",
+	"let identity = fn(x) { return x; }; identity(5);",
+	"let double = fn(x) { x * 2 ;}; double(5);",
+	"let add = fn(x, y) { x + y ;}; add(5, 5);",
+	"let add = fn(x, y) { x + y ;}; add(5 + 5, add(5, 5));",
+	"fn(x) { x; }(5);",
+	`
+	let newAdder = fn(x) {
+	fn(y) { x + y };
+	};
+	let addTwo = newAdder(2);
+	addTwo(2);`,
+	"5(1)",
+	"let f = fn() { let g = fn() { x }; g() }; let x = 4; f()",
+	"let f = fn() { let g = fn() { x }; if (true) { let x = 2; }; g() }; let x = 1; f()",
+	"let f = fn(a) { let g = fn() { a + 1 }; let r = g(); r * 10 }; f(1)",
+	"let f = fn(a) { a }; f(1, 2)",
+	"let f = fn(a, b) { a }; f(1)",
+	"fn() { 1 }(1)",
+
+	// Strings
+	`"hello" + " " + "world"`,
+	`"a" == "a"`, `"a" != "a"`, `"abc" < "abd"`, `"b" >= "abc"`,
+	`"ab" * 3`, `3 * "ab"`, `"ab" * -1`,
+	`"ell" in "hello"`, `"xyz" in "hello"`, `1 in "abc"`,
+
+	// Function declarations
+	`fn add(a, b) { a + b }; add(1, 2)`,
+	`let sq = fn(x) { x * x }; sq`,
+	`let r = isEven(10); fn isEven(n) { if (n == 0) { true } else { isOdd(n - 1) } } fn isOdd(n) { if (n == 0) { false } else { isEven(n - 1) } } r`,
+	`let f = fn() { let y = g(2); fn g(x) { x * 3 } y }; f()`,
+	`fn named() { 1 }; named`,
+
+	// Block scoping
+	"let a = 1; if (true) { let a = 2; a }",
+	"let a = 1; if (true) { let a = 2; }; a",
+	"if (true) { let b = 1; }; b",
+	"let f = fn(x) { let a = 1; if (x) { let a = 2; let b = a * 10; b + a } else { a } }; f(true) + f(false)",
+	"let f = fn() { if (true) { let a = 3; fn() { a } } }; f()()",
+	"const c = 4; if (true) { const c = 5; c } + c",
+
+	// Big integers
+	"9223372036854775807 + 1",
+	"let f = fn(n) { if (n < 2) { 1 } else { n * f(n - 1) } }; f(25)",
+	"let depth = fn(n) { if (n == 0) { 0 } else { 1 + depth(n - 1) } }; depth(20000)",
+	"(9223372036854775807 + 1) - 1",
+	"-(9223372036854775807 + 1) < 0",
+
+	// Builtins
+	`sprintf("%d-%s", 1, "a")`,
+	`let sprintf = fn(x) { x }; sprintf(2)`,
+	`let f = fn(x) { sprintf("%q", x) }; f(true)`,
+	`sprintf(1)`,
+	`sprintf`,
+}
+
+func TestParityWithEval(t *testing.T) {
+	for _, input := range parityInputs {
+		expected := eval.Eval(parse(input), object.NewEnvironment())
+		got := runVM(t, input)
+
+		if got.Type() != expected.Type() {
+			t.Errorf("input %q: wrong type. Expected=%s, got=%s", input, expected.Type(), got.Type())
+			continue
+		}
+
+		if got.Inspect() != expected.Inspect() {
+			t.Errorf("input %q: wrong value. Expected=%q, got=%q", input, expected.Inspect(), got.Inspect())
+		}
+
+		if errObj, ok := expected.(*object.Error); ok && got.(*object.Error).KindName() != errObj.KindName() {
+			t.Errorf("input %q: wrong error kind. Expected=%s, got=%s", input, errObj.KindName(), got.(*object.Error).KindName())
+		}
+	}
+}
+
+func TestRecursiveFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{
+			`let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } }; fib(15);`,
+			610,
+		},
+		{
+			`let wrapper = fn() {
+				let countDown = fn(x) { if (x == 0) { return 0; } countDown(x - 1); };
+				countDown(3);
+			};
+			wrapper();`,
+			0,
+		},
+		{
+			`let f = fn() { g() }; let g = fn() { 3 }; f();`,
+			3,
+		},
+	}
+
+	for _, tt := range tests {
+		result := runVM(t, tt.input)
+
+		integer, ok := result.(*object.Integer)
+		if !ok {
+			t.Errorf("object is not of type Integer. Got=%T (%+v)", result, result)
+			continue
+		}
+		if integer.Value != tt.expected {
+			t.Errorf("object has wrong value. Expect=%d, got=%d", tt.expected, integer.Value)
+		}
+	}
+}
+
+// Calls nest up to MaxFrames deep, the stack growing along.
+func TestStackOverflow(t *testing.T) {
+	// The program's own frame and the call of f(0) leave room for MaxFrames-2 more calls.
+	deepest := runVM(t, fmt.Sprintf("let f = fn(n) { if (n == 0) { 0 } else { 1 + f(n - 1) } }; f(%d);", MaxFrames-2))
+	if integer, ok := deepest.(*object.Integer); !ok || integer.Value != MaxFrames-2 {
+		t.Errorf("calls should nest %d deep. Got=%s", MaxFrames-1, deepest.Inspect())
+	}
+
+	result := runVM(t, "let f = fn(x) { f(x) }; f(1);")
+
+	errObj, ok := result.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. Got=%T(%+v)", result, result)
+	}
+	if errObj.Message != STACK_OVERFLOW {
+		t.Errorf("wrong error message. Expected=%q, got=%q", STACK_OVERFLOW, errObj.Message)
+	}
+}
+
+// Programs print to eval.Output, as they do under the evaluator.
+func TestOutputBuiltins(t *testing.T) {
+	var out strings.Builder
+	defer func(previous io.Writer) { eval.Output = previous }(eval.Output)
+	eval.Output = &out
+
+	input := `let greet = fn(name) { println("hello", name) }; greet("vm"); print(1, true); printf("%d%%", 50)`
+	result := runVM(t, input)
+
+	if result != eval.NULL {
+		t.Errorf("wrong result. Expected=null, got=%s", result.Inspect())
+	}
+	if out.String() != "hello vm\n1 true50%" {
+		t.Errorf("wrong output. Expected=%q, got=%q", "hello vm\n1 true50%", out.String())
+	}
+}
+
+func runVM(t *testing.T, input string) object.Object {
+	t.Helper()
+
+	comp := compiler.New()
+	if err := comp.Compile(parse(input)); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	return New(comp.Bytecode()).Run()
+}
+
+func parse(input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	return p.ParseProgram()
+}